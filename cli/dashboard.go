@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ticker-es/client-go/client"
+)
+
+const sparklineWidth = 40
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboard renders successive Samples of the server state, redrawing the terminal each time.
+type dashboard struct {
+	address     string
+	last        *client.Sample
+	eventRates  []float64
+	connections []float64
+}
+
+func (d *dashboard) render(w io.Writer, sample client.Sample, err error) {
+	if err == nil {
+		d.last = &sample
+		d.eventRates = appendWindow(d.eventRates, sample.EventsPerSecond)
+		d.connections = appendWindow(d.connections, float64(sample.ConnectionCount))
+	}
+	var b strings.Builder
+	b.WriteString("\033[H\033[2J")
+	fmt.Fprintf(&b, "ticker server %s\n\n", d.address)
+	if err != nil {
+		fmt.Fprintf(&b, "error: %s\n\n", err)
+	}
+	if d.last != nil {
+		fmt.Fprintf(&b, "uptime            %s\n", d.last.Uptime)
+		fmt.Fprintf(&b, "events stored     %d\n", d.last.EventCount)
+		fmt.Fprintf(&b, "events/sec        %-10.1f %s\n", d.last.EventsPerSecond, sparkline(d.eventRates))
+		fmt.Fprintf(&b, "connections       %-10d %s\n", d.last.ConnectionCount, sparkline(d.connections))
+		fmt.Fprintf(&b, "connections/sec   %+.1f\n", d.last.ConnectionsPerSecond)
+		fmt.Fprintf(&b, "\nsampled at %s, press Ctrl-C to quit\n", d.last.SampledAt.Format("15:04:05"))
+	}
+	io.WriteString(w, b.String())
+}
+
+func appendWindow(values []float64, value float64) []float64 {
+	values = append(values, value)
+	if len(values) > sparklineWidth {
+		values = values[len(values)-sparklineWidth:]
+	}
+	return values
+}
+
+// sparkline renders the values as bars scaled between their minimum and maximum.
+func sparkline(values []float64) string {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		index := 0
+		if max > min {
+			index = int((v - min) / (max - min) * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[index])
+	}
+	return b.String()
+}
+
+// sampleJSON is the representation of a Sample in --format json, with plain units for monitoring systems.
+type sampleJSON struct {
+	SampledAt            time.Time `json:"sampled_at"`
+	UptimeSeconds        int64     `json:"uptime_seconds"`
+	ConnectionCount      int       `json:"connection_count"`
+	EventCount           int64     `json:"event_count"`
+	EventsPerSecond      float64   `json:"events_per_second"`
+	ConnectionsPerSecond float64   `json:"connections_per_second"`
+	Error                string    `json:"error,omitempty"`
+}
+
+func toSampleJSON(sample client.Sample, err error) sampleJSON {
+	if err != nil {
+		return sampleJSON{SampledAt: time.Now(), Error: err.Error()}
+	}
+	return sampleJSON{
+		SampledAt:            sample.SampledAt,
+		UptimeSeconds:        int64(sample.Uptime.Seconds()),
+		ConnectionCount:      sample.ConnectionCount,
+		EventCount:           sample.EventCount,
+		EventsPerSecond:      sample.EventsPerSecond,
+		ConnectionsPerSecond: sample.ConnectionsPerSecond,
+	}
+}
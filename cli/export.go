@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ticker-es/client-go/eventstream/archive"
+	"github.com/ticker-es/client-go/support"
+)
+
+func executeExport(cmd *cobra.Command, args []string) {
+	output, _ := cmd.Flags().GetString("output")
+	resume, _ := cmd.Flags().GetBool("resume")
+	compressionName, _ := cmd.Flags().GetString("compression")
+	compression := archive.CompressionFor(output)
+	if compressionName != "auto" {
+		var err error
+		if compression, err = archive.ParseCompression(compressionName); err != nil {
+			exit(err)
+		}
+	}
+	if resume && (cmd.Flags().Changed("range") || cmd.Flags().Changed("selector") || cmd.Flags().Changed("compression")) {
+		exit(fmt.Errorf("--range, --selector and --compression are taken from the manifest when resuming"))
+	}
+	var w *archive.Writer
+	var err error
+	if resume {
+		if w, err = archive.Resume(output); err != nil {
+			exit(fmt.Errorf("can't resume export: %w", err))
+		}
+	} else if _, err := os.Stat(output); err == nil {
+		exit(fmt.Errorf("%s already exists, use --resume to continue it", output))
+	}
+	selector := selectorFromFlags(cmd)
+	bracket := bracketFromFlags(cmd)
+	cl := connect()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	if w == nil {
+		resolved, err := cl.ResolveBracket(ctx, *bracket)
+		if err != nil {
+			exit(fmt.Errorf("can't resolve --range: %w", err))
+		}
+		if w, err = archive.Create(output, compression, *selector, resolved); err != nil {
+			exit(err)
+		}
+	}
+	manifest := w.Manifest()
+	*selector = manifest.Selector
+	*bracket = manifest.Remaining()
+	before := manifest.EventCount
+	_, err = cl.Stream(ctx, selector, bracket, w.Write)
+	// The archive is completed in any case, so an interrupted export can be resumed
+	if cerr := w.Close(); cerr != nil {
+		exit(fmt.Errorf("can't complete %s: %w", output, cerr))
+	}
+	manifest = w.Manifest()
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Export interrupted after sequence %d, continue it with --resume\n", manifest.LastSequence)
+		} else {
+			fmt.Fprintf(os.Stderr, "Export failed after sequence %d, continue it with --resume: %s\n", manifest.LastSequence, err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d events to %s (%d in total, sequences %d to %d, sha256 %s)\n",
+		manifest.EventCount-before, output, manifest.EventCount, manifest.FirstSequence, manifest.LastSequence, manifest.SHA256)
+}
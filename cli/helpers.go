@@ -1,48 +1,98 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
-	"strconv"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/ticker-es/client-go/client"
 	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/claimcheck"
+	"github.com/ticker-es/client-go/eventstream/filter"
+	"github.com/ticker-es/client-go/eventstream/upcast"
 )
 
 func selectorFromFlags(cmd *cobra.Command) *base.Selector {
-	sel, _ := cmd.Flags().GetString("selector")
-	if selector, err := base.ParseSelector(sel); err == nil {
-		return selector
+	sels, _ := cmd.Flags().GetStringArray("selector")
+	var selectors []base.Selector
+	for _, sel := range sels {
+		selector, err := base.ParseSelector(sel)
+		if err != nil {
+			panic(fmt.Errorf("invalid selector %q: %w", sel, err))
+		}
+		selectors = append(selectors, *selector)
 	}
-	return nil
+	selector := base.AnyOf(selectors...)
+	return &selector
 }
 
-func bracketFromFlags(cmd *cobra.Command) *base.Bracket {
-	rang, err := cmd.Flags().GetString("range")
-	if err != nil {
-		panic(err)
+// whereFromFlags wraps the handler with the filter given in --where, if any.
+func whereFromFlags(cmd *cobra.Command, handler base.EventHandler) base.EventHandler {
+	where, _ := cmd.Flags().GetString("where")
+	if where == "" {
+		return handler
 	}
-	ranges := strings.Split(rang, ":")
-	first, err := strconv.ParseInt(ranges[0], 10, 64)
+	f, err := filter.Compile(where)
 	if err != nil {
-		first = 0
+		exit(fmt.Errorf("invalid --where expression: %w", err))
+	}
+	return f.Handler(handler)
+}
+
+// exit reports a usage error and terminates the command.
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
+
+// confirm asks the question on stdout and reports whether it was answered with yes.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
 	}
-	last, err := strconv.ParseInt(ranges[1], 10, 64)
+	return false
+}
+
+func bracketFromFlags(cmd *cobra.Command) *base.Bracket {
+	rang, _ := cmd.Flags().GetString("range")
+	bracket, err := base.ParseBracket(rang)
 	if err != nil {
-		last = -1
+		exit(err)
 	}
-	return &base.Bracket{
-		NextSequence: first,
-		LastSequence: last,
+	return &bracket
+}
+
+// timeRangeFromFlags parses --since and --until and reports whether any of them was given.
+func timeRangeFromFlags(cmd *cobra.Command) (base.TimeRange, bool) {
+	var timeRange base.TimeRange
+	now := time.Now()
+	for flag, bound := range map[string]*time.Time{"since": &timeRange.Since, "until": &timeRange.Until} {
+		value, _ := cmd.Flags().GetString(flag)
+		if value == "" {
+			continue
+		}
+		t, err := base.ParseTime(value, now)
+		if err != nil {
+			exit(fmt.Errorf("invalid --%s: %w", flag, err))
+		}
+		*bound = t
 	}
+	return timeRange, !timeRange.Since.IsZero() || !timeRange.Until.IsZero()
 }
 
 func createFormatter(cmd *cobra.Command) client.Formatter {
 	format, _ := cmd.Flags().GetString("format")
 	omitPayload, _ := cmd.Flags().GetBool("omit-payload")
 	pretty, _ := cmd.Flags().GetBool("pretty")
+	upcasters, _ := cmd.Flags().GetString("upcasters")
 	var formatter client.Formatter
 	switch strings.ToLower(format) {
 	case "json":
@@ -50,12 +100,39 @@ func createFormatter(cmd *cobra.Command) client.Formatter {
 	default:
 		formatter = client.TextFormatter(pretty)
 	}
+	if upcasters != "" {
+		registry := upcast.NewRegistry()
+		if err := registry.ReadRulesFile(upcasters); err != nil {
+			panic(err)
+		}
+		formatter = client.UpcastPayload(formatter, registry)
+	}
+	if claimCheck := claimCheckFromFlags(cmd); claimCheck != nil {
+		formatter = client.ResolveClaimChecks(formatter, claimCheck)
+	}
 	if omitPayload {
 		formatter = client.OmitPayload(formatter)
 	}
 	return formatter
 }
 
+func claimCheckFromFlags(cmd *cobra.Command) *claimcheck.ClaimCheck {
+	directory, _ := cmd.Flags().GetString("blob-store")
+	if directory == "" {
+		return nil
+	}
+	store, err := claimcheck.NewFileBlobStore(directory)
+	if err != nil {
+		panic(err)
+	}
+	var opts []claimcheck.Option
+	if cmd.Flags().Lookup("claim-threshold") != nil {
+		threshold, _ := cmd.Flags().GetInt("claim-threshold")
+		opts = append(opts, claimcheck.Threshold(threshold))
+	}
+	return claimcheck.NewClaimCheck(store, opts...)
+}
+
 func loadEvents(files ...string) []base.Event {
 	var events []base.Event
 	for _, arg := range files {
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ticker-es/client-go/eventstream/archive"
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/filter"
+	"github.com/ticker-es/client-go/support"
+)
+
+// importOrigin is where an imported Event has been read from, for the failure report.
+type importOrigin struct {
+	file   string
+	record int
+}
+
+func (o importOrigin) String() string {
+	return fmt.Sprintf("%s:%d", o.file, o.record)
+}
+
+// importStats collects the outcome of an import and reports failures as they happen.
+type importStats struct {
+	sync.Mutex
+	read, emitted, skipped, failed int
+}
+
+func (s *importStats) fail(origin fmt.Stringer, err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.failed++
+	fmt.Fprintf(os.Stderr, "%s: %s\n", origin, err)
+}
+
+func executeImport(cmd *cobra.Command, args []string) {
+	dedupe, _ := cmd.Flags().GetString("dedupe")
+	idFieldName, _ := cmd.Flags().GetString("id-field")
+	inFlight, _ := cmd.Flags().GetInt("in-flight")
+	var key func(e *base.Event) string
+	switch dedupe {
+	case "none":
+	case "id":
+		idField, err := filter.CompileField(idFieldName)
+		if err != nil {
+			exit(fmt.Errorf("invalid --id-field: %w", err))
+		}
+		key = func(e *base.Event) string {
+			if id := idField.Value(e); id != nil {
+				return fmt.Sprint(id)
+			}
+			return ""
+		}
+	case "hash":
+		key = func(e *base.Event) string {
+			return e.ContentHash()
+		}
+	default:
+		exit(fmt.Errorf("unknown --dedupe %q (none, id, hash)", dedupe))
+	}
+	if inFlight < 1 {
+		exit(fmt.Errorf("invalid --in-flight %d", inFlight))
+	}
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	// Exports are verified up front, so a damaged one isn't imported partially
+	manifests := make(map[string]*archive.Manifest)
+	for _, file := range args {
+		if file == "-" {
+			continue
+		}
+		if _, err := os.Stat(archive.ManifestPath(file)); err == nil {
+			manifest, err := archive.Verify(file)
+			if err != nil {
+				exit(err)
+			}
+			manifests[file] = manifest
+		}
+	}
+
+	cl := connect()
+	defer cl.Close()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	seen := make(map[string]bool)
+	if key != nil {
+		selector, bracket := base.Select(), base.All()
+		_, err := cl.Stream(ctx, &selector, &bracket, func(e *base.Event) error {
+			if k := key(e); k != "" {
+				seen[k] = true
+			}
+			return nil
+		})
+		if err != nil {
+			exit(fmt.Errorf("can't read the existing events for --dedupe: %w", err))
+		}
+	}
+
+	stats := &importStats{}
+	// origins holds where the Events in flight have been read, by their index in EmitAll
+	origins := make(map[int]importOrigin)
+	var originsMutex sync.Mutex
+	var next int
+	events := make(chan base.Event)
+	go func() {
+		defer close(events)
+		for _, file := range args {
+			read := readImportFile(ctx, file, stats, func(e *base.Event, origin importOrigin) bool {
+				if len(e.Aggregate) == 0 || e.Type == "" {
+					stats.fail(origin, errors.New("event without aggregate or type"))
+					return true
+				}
+				if key != nil {
+					if k := key(e); k != "" {
+						if seen[k] {
+							stats.Lock()
+							stats.skipped++
+							stats.Unlock()
+							return true
+						}
+						seen[k] = true
+					}
+				}
+				e.Sequence = 0
+				originsMutex.Lock()
+				origins[next] = origin
+				next++
+				originsMutex.Unlock()
+				select {
+				case events <- *e:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+			if manifest, ok := manifests[file]; ok && ctx.Err() == nil && int64(read) != manifest.EventCount {
+				stats.fail(importOrigin{file, read}, fmt.Errorf("read %d events, but the manifest lists %d", read, manifest.EventCount))
+			}
+		}
+	}()
+	err := cl.EmitAll(ctx, events, inFlight, func(index int, event base.Event, err error) {
+		originsMutex.Lock()
+		origin := origins[index]
+		delete(origins, index)
+		originsMutex.Unlock()
+		if err != nil {
+			stats.fail(origin, err)
+			return
+		}
+		stats.Lock()
+		stats.emitted++
+		stats.Unlock()
+	})
+
+	stats.Lock()
+	defer stats.Unlock()
+	if err != nil {
+		fmt.Printf("Import interrupted after reading %d events: ", stats.read)
+	} else {
+		fmt.Printf("Read %d events: ", stats.read)
+	}
+	fmt.Printf("emitted %d, skipped %d duplicates, %d failed\n", stats.emitted, stats.skipped, stats.failed)
+	if err != nil || stats.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// readImportFile reads the Events from the file (- for stdin), passing them to the handler until it returns false.
+// Records which can't be read are reported as failures. It returns the number of records read.
+func readImportFile(ctx context.Context, file string, stats *importStats, handler func(e *base.Event, origin importOrigin) bool) int {
+	var r *archive.Reader
+	var err error
+	if file == "-" {
+		r, err = archive.NewReader(os.Stdin)
+		file = "stdin"
+	} else {
+		r, err = archive.Open(file)
+	}
+	if err != nil {
+		stats.fail(importOrigin{file, 0}, err)
+		return 0
+	}
+	defer r.Close()
+	for ctx.Err() == nil {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		var recordErr *archive.RecordError
+		if errors.As(err, &recordErr) {
+			stats.Lock()
+			stats.read++
+			stats.Unlock()
+			stats.fail(importOrigin{file, recordErr.Record}, recordErr.Err)
+			continue
+		} else if err != nil {
+			stats.fail(importOrigin{file, r.Record()}, err)
+			break
+		}
+		stats.Lock()
+		stats.read++
+		stats.Unlock()
+		if !handler(e, importOrigin{file, r.Record()}) {
+			break
+		}
+	}
+	return r.Record()
+}
@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/ticker-es/client-go/config"
 	"github.com/vbauerster/mpb/v7"
 	"github.com/vbauerster/mpb/v7/decor"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/ticker-es/client-go/client"
 	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/claimcheck"
 	"github.com/ticker-es/client-go/support"
 )
 
@@ -39,6 +43,8 @@ var (
 			Flag("topic", Str(""), Abbr("t"), Description("Select Topic and Type of the emitted event"), Persistent()),
 			Flag("payload", Str("{}"), Abbr("p"), Description("The payload of the emitted event (- for stdin)"), Persistent()),
 			Flag("from-stdin", Bool(), Description("Read events to be emitted from stdin"), Persistent()),
+			Flag("blob-store", Str(""), Description("Offload large payloads into this directory"), Persistent()),
+			Flag("claim-threshold", Int(claimcheck.DefaultThreshold), Description("Payload size (in bytes) above which payloads get offloaded"), Persistent()),
 			Run(executeEmit),
 		),
 		SubCommand("play",
@@ -59,23 +65,92 @@ var (
 			Flag("format", Str("text"), Description("Format for Event output (text, json)"), Persistent()),
 			Flag("omit-payload", Bool(), Description("Omit Payload in Event output"), Persistent()),
 			Flag("pretty", Bool(), Description("Use pretty-mode in Event output"), Persistent()),
-			Flag("selector", Str("/"), Abbr("s"), Description("Select which events to stream"), Persistent()),
-			Flag("range", Str("1:"), Abbr("r"), Description("Select which events to stream"), Persistent()),
+			Flag("upcasters", Str(""), Description("Upcast Event payloads according to the rules in this file"), Persistent()),
+			Flag("blob-store", Str(""), Description("Resolve offloaded payloads from this directory"), Persistent()),
+			config.FlagSelector("Select which events to stream"),
+			Flag("range", Str("1:"), Abbr("r"), Description("Select which events to stream (N, N:M, N:, :M, -100: for the last 100, head, tail)"), Persistent()),
+			Flag("since", Str(""), Description("Stream events which occurred since this time (RFC3339, 15:04, or relative like -2h)"), Persistent()),
+			Flag("until", Str(""), Description("Stream events which occurred until this time (RFC3339, 15:04, or relative like -2h)"), Persistent()),
+			config.FlagWhere("Only stream events matching this expression"),
 			Flag("simulate-delay", Int(0), Description("Wait some time (in ms) until processing the next Event"), Persistent(), Env()),
 			Run(executeStream),
 		),
+		SubCommand("export",
+			Short("Export a portion of the event stream into a (compressed) NDJSON file with a manifest"),
+			Flag("output", Str(""), Abbr("o"), Description("File to export to (.gz and .zst select the compression)"), Mandatory(), Persistent()),
+			Flag("compression", Str("auto"), Description("Compression of the file (auto, none, gzip, zstd)"), Persistent()),
+			config.FlagSelector("Select which events to export"),
+			Flag("range", Str("1:"), Abbr("r"), Description("Select which events to export (N, N:M, N:, :M, -100: for the last 100, head, tail)"), Persistent()),
+			Flag("resume", Bool(), Description("Continue an existing export after its last checkpoint, also when it has been killed, with the selector and range from its manifest"), Persistent()),
+			Run(executeExport),
+		),
+		SubCommand("import",
+			Short("Import events from NDJSON or JSON array files (optionally compressed, as written by export; - or none for stdin)"),
+			Flag("dedupe", Str("none"), Description("Skip events which are already stored or imported (none, id, hash of aggregate, type, occurred_at and payload)"), Persistent()),
+			Flag("id-field", Str("payload.id"), Description("Field which identifies an event for --dedupe id"), Persistent()),
+			Flag("in-flight", Int(client.DefaultInFlight), Description("Number of events emitted concurrently (1 keeps the order across aggregates)"), Persistent()),
+			Run(executeImport),
+		),
+		SubCommand("get",
+			Short("Print the events with the given sequences"),
+			Flag("format", Str("text"), Description("Format for Event output (text, json)"), Persistent()),
+			Flag("omit-payload", Bool(), Description("Omit Payload in Event output"), Persistent()),
+			Flag("pretty", Bool(), Description("Use pretty-mode in Event output"), Persistent()),
+			Flag("upcasters", Str(""), Description("Upcast Event payloads according to the rules in this file"), Persistent()),
+			Flag("blob-store", Str(""), Description("Resolve offloaded payloads from this directory"), Persistent()),
+			Args(cobra.MinimumNArgs(1)),
+			Run(executeGet),
+		),
+		SubCommand("listen",
+			Short("Listen to newly emitted events"),
+			Flag("format", Str("text"), Description("Format for Event output (text, json)"), Persistent()),
+			Flag("omit-payload", Bool(), Description("Omit Payload in Event output"), Persistent()),
+			Flag("pretty", Bool(), Description("Use pretty-mode in Event output"), Persistent()),
+			Flag("upcasters", Str(""), Description("Upcast Event payloads according to the rules in this file"), Persistent()),
+			Flag("blob-store", Str(""), Description("Resolve offloaded payloads from this directory"), Persistent()),
+			config.FlagSelector("Select which events to listen to"),
+			config.FlagWhere("Only listen to events matching this expression"),
+			Run(executeListen),
+		),
 		SubCommand("subscribe",
 			Short("Subscribe to a specific event stream"),
 			Flag("format", Str("text"), Description("Format for Event output (text, json)"), Persistent()),
 			Flag("omit-payload", Bool(), Description("Omit Payload in Event output"), Persistent()),
 			Flag("pretty", Bool(), Description("Use pretty-mode in Event output"), Persistent()),
-			Flag("selector", Str("/"), Abbr("s"), Description("Select which events to subscribe to"), Persistent()),
+			Flag("upcasters", Str(""), Description("Upcast Event payloads according to the rules in this file"), Persistent()),
+			Flag("blob-store", Str(""), Description("Resolve offloaded payloads from this directory"), Persistent()),
+			config.FlagSelector("Select which events to subscribe to"),
 			Flag("client-id", Str(""), Abbr("i"), Description("Unique Identifier for this subscription"), Mandatory(), Persistent(), Env()),
+			config.FlagWhere("Only handle subscribed events matching this expression"),
 			Flag("simulate-delay", Int(0), Description("Wait some time (in ms) until processing the next Event"), Persistent(), Env()),
 			Run(executeSubscribe),
 		),
+		SubCommand("mirror",
+			Short("Replicate events from one ticker server into another, resuming where it stopped"),
+			Flag("source", Str(""), Description("Address of the server to replicate from"), Mandatory(), Persistent()),
+			Flag("target", Str(""), Description("Address of the server to replicate into"), Mandatory(), Persistent()),
+			Flag("client-id", Str(""), Abbr("i"), Description("Unique Identifier for the subscription on the source"), Mandatory(), Persistent()),
+			Flag("mapping", Str(""), Description("File keeping the mapping of source to target sequences (default <client-id>.mapping)"), Persistent()),
+			config.FlagSelector("Select which events to replicate"),
+			Flag("interval", Duration(10*time.Second), Description("How often to report the replication lag (0 to disable)"), Persistent()),
+			Run(executeMirror),
+		),
+		SubCommand("shutdown",
+			Short("Shut down the ticker server gracefully"),
+			Flag("grace-period", Duration(30*time.Second), Description("How long to wait for running calls before forcing the server down"), Persistent()),
+			Flag("yes", Bool(), Abbr("y"), Description("Do not ask for confirmation"), Persistent()),
+			Run(executeShutdown),
+		),
+		SubCommand("ping",
+			Short("Check connectivity to the ticker server and measure its latency"),
+			Flag("count", Int(10), Abbr("n"), Description("Number of calls to measure the round-trip latency with"), Persistent()),
+			Flag("timeout", Duration(5*time.Second), Description("Timeout for each step and call"), Persistent()),
+			Run(executePing),
+		),
 		SubCommand("metrics",
 			Short("Show live metrics of the ticker server"),
+			Flag("format", Str("dashboard"), Description("Format for metrics output (dashboard, json)"), Persistent()),
+			Flag("interval", Duration(client.DefaultSampleInterval), Description("How often to sample the server state"), Persistent()),
 			Run(executeMetrics),
 		),
 		Version(version, commit),
@@ -97,7 +172,11 @@ func main() {
 func executeEmit(cmd *cobra.Command, args []string) {
 	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
 	fromStdin, _ := cmd.Flags().GetBool("from-stdin")
-	cl := connect()
+	var opts []client.Option
+	if claimCheck := claimCheckFromFlags(cmd); claimCheck != nil {
+		opts = append(opts, client.Use(claimCheck))
+	}
+	cl := connect(opts...)
 	if fromStdin {
 		dec := json.NewDecoder(os.Stdin)
 		for {
@@ -117,15 +196,17 @@ func executeEmit(cmd *cobra.Command, args []string) {
 		payloadString, _ := cmd.Flags().GetString("payload")
 		topicAndType, _ := cmd.Flags().GetString("topic")
 		if selector, err := base.ParseSelector(topicAndType); err == nil {
+			if !selector.IsComplete() {
+				panic(fmt.Errorf("topic %q must name a single aggregate and type", topicAndType))
+			}
 			var payload map[string]interface{}
 			if err := json.Unmarshal([]byte(payloadString), &payload); err != nil {
 				panic(err)
 			}
 			event := base.Event{
-				Aggregate:  selector.Aggregate,
-				Type:       selector.Type,
-				OccurredAt: time.Now(),
-				Payload:    payload,
+				Aggregate: selector.Aggregate,
+				Type:      selector.Type,
+				Payload:   payload,
 			}
 			if _, err := cl.Emit(ctx, event); err != nil {
 				panic(err)
@@ -186,32 +267,94 @@ func executeSample(cmd *cobra.Command, args []string) {
 func executeStream(cmd *cobra.Command, args []string) {
 	formatter := createFormatter(cmd)
 	simulateDelay := viper.GetInt("simulate-delay")
-	cl := connect()
-	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
-	count, err := cl.Stream(ctx, selectorFromFlags(cmd), bracketFromFlags(cmd), func(e *base.Event) error {
+	handler := whereFromFlags(cmd, func(e *base.Event) error {
 		if simulateDelay != 0 {
 			time.Sleep(time.Duration(simulateDelay) * time.Millisecond)
 		}
 		return formatter(os.Stdout, e)
 	})
+	bracket := bracketFromFlags(cmd)
+	timeRange, hasTimeRange := timeRangeFromFlags(cmd)
+	cl := connect()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
+	if hasTimeRange {
+		resolved, err := cl.ResolveBracket(ctx, *bracket)
+		if err != nil {
+			panic(err)
+		}
+		inTime, err := cl.ResolveTimeRange(ctx, timeRange)
+		if err != nil {
+			panic(err)
+		}
+		*bracket = resolved.Intersect(inTime)
+	}
+	count, err := cl.Stream(ctx, selectorFromFlags(cmd), bracket, handler)
 	fmt.Printf("Handled %d events\n", count)
 	if err != nil {
 		panic(err)
 	}
 }
 
+func executeGet(cmd *cobra.Command, args []string) {
+	formatter := createFormatter(cmd)
+	sequences := make([]int64, len(args))
+	for i, arg := range args {
+		sequence, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || sequence < 1 {
+			exit(fmt.Errorf("invalid sequence %q", arg))
+		}
+		sequences[i] = sequence
+	}
+	cl := connect()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
+	missing := 0
+	for _, sequence := range sequences {
+		event, err := cl.Get(ctx, sequence)
+		if errors.Is(err, base.ErrSequenceNotFound) {
+			fmt.Fprintf(os.Stderr, "Event %d not found\n", sequence)
+			missing++
+			continue
+		} else if err != nil {
+			panic(err)
+		}
+		if err := formatter(os.Stdout, event); err != nil {
+			panic(err)
+		}
+	}
+	if missing > 0 {
+		os.Exit(1)
+	}
+}
+
+func executeListen(cmd *cobra.Command, args []string) {
+	formatter := createFormatter(cmd)
+	handler := whereFromFlags(cmd, func(e *base.Event) error {
+		return formatter(os.Stdout, e)
+	})
+	cl := connect()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
+	err := cl.Listen(ctx, selectorFromFlags(cmd), handler)
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return
+		}
+		panic(err)
+	}
+}
+
 func executeSubscribe(cmd *cobra.Command, args []string) {
 	formatter := createFormatter(cmd)
 	clientID := viper.GetString("client_id")
 	simulateDelay := viper.GetInt("simulate-delay")
-	cl := connect()
-	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
-	err := cl.Subscribe(ctx, clientID, selectorFromFlags(cmd), func(e *base.Event) error {
+	handler := whereFromFlags(cmd, func(e *base.Event) error {
 		if simulateDelay != 0 {
 			time.Sleep(time.Duration(simulateDelay) * time.Millisecond)
 		}
 		return formatter(os.Stdout, e)
 	})
+	cl := connect()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
+	err := cl.Subscribe(ctx, clientID, selectorFromFlags(cmd), handler)
 	if err != nil {
 		if ctx.Err() == context.Canceled {
 			return
@@ -220,24 +363,84 @@ func executeSubscribe(cmd *cobra.Command, args []string) {
 	}
 }
 
-func executeMetrics(cmd *cobra.Command, args []string) {
+func executeShutdown(cmd *cobra.Command, args []string) {
+	gracePeriod, _ := cmd.Flags().GetDuration("grace-period")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if gracePeriod < 0 {
+		exit(fmt.Errorf("invalid --grace-period %s", gracePeriod))
+	}
+	address := viper.GetString("connect")
+	if !yes && !confirm(fmt.Sprintf("Shut down the ticker server at %s?", address)) {
+		fmt.Println("Aborted")
+		return
+	}
 	cl := connect()
 	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
-	for {
-		cl.PrintServerState(ctx)
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(2 * time.Second):
+	start := time.Now()
+	if err := cl.Shutdown(ctx, gracePeriod); err != nil {
+		exit(fmt.Errorf("can't shut down the server at %s: %w", address, err))
+	}
+	fmt.Printf("Shutdown requested, waiting %s for the server to drain its open streams and go away\n", gracePeriod)
+	// Give the server some slack beyond the grace period to actually close its listener
+	waitCtx, cancel := context.WithTimeout(ctx, gracePeriod+10*time.Second)
+	defer cancel()
+	if err := cl.AwaitShutdown(waitCtx, gracePeriod); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "Server at %s is still running after %s\n", address, time.Since(start).Round(time.Millisecond))
+			os.Exit(1)
 		}
+		exit(fmt.Errorf("can't tell whether the server at %s has shut down: %w", address, err))
 	}
+	fmt.Printf("Server at %s has shut down after %s\n", address, time.Since(start).Round(time.Millisecond))
 }
 
-func connect() *client.Client {
+func executeMetrics(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	format = strings.ToLower(format)
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if format != "dashboard" && format != "json" {
+		exit(fmt.Errorf("unknown format %q", format))
+	}
+	if interval <= 0 {
+		exit(fmt.Errorf("invalid --interval %s", interval))
+	}
+	var handler func(sample client.Sample, err error) error
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		handler = func(sample client.Sample, err error) error {
+			return enc.Encode(toSampleJSON(sample, err))
+		}
+	} else {
+		d := &dashboard{address: viper.GetString("connect")}
+		handler = func(sample client.Sample, err error) error {
+			d.render(os.Stdout, sample, err)
+			return nil
+		}
+	}
+	cl := connect()
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
+	if err := cl.SampleServerState(ctx, interval, handler); err != nil && ctx.Err() == nil {
+		panic(err)
+	}
+}
+
+func connect(opts ...client.Option) *client.Client {
+	return connectTo(viper.GetString("connect"), opts...)
+}
+
+// connectTo connects to the server at the address with the credentials given by the flags.
+func connectTo(address string, opts ...client.Option) *client.Client {
+	if token := viper.GetString("token"); token != "" {
+		opts = append(opts, client.AuthenticationToken(token))
+	}
+	if viper.GetBool("insecure") {
+		return config.ConnectInsecure(address, opts...)
+	}
 	return config.Connect(
-		viper.GetString("connect"),
+		address,
 		viper.GetString("ca_cert"),
 		viper.GetString("client_cert"),
 		viper.GetString("client_key"),
+		opts...,
 	)
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/support"
+)
+
+func executeMirror(cmd *cobra.Command, args []string) {
+	sourceAddress, _ := cmd.Flags().GetString("source")
+	targetAddress, _ := cmd.Flags().GetString("target")
+	mappingFile, _ := cmd.Flags().GetString("mapping")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	clientID, _ := cmd.Flags().GetString("client-id")
+	if sourceAddress == targetAddress {
+		exit(fmt.Errorf("--source and --target must be different servers"))
+	}
+	if interval < 0 {
+		exit(fmt.Errorf("invalid --interval %s", interval))
+	}
+	if mappingFile == "" {
+		mappingFile = clientID + ".mapping"
+	}
+	selector := selectorFromFlags(cmd)
+	mapping, err := client.OpenSequenceMapping(mappingFile)
+	if err != nil {
+		exit(err)
+	}
+	defer mapping.Close()
+	source := connectTo(sourceAddress, client.Reconnect(client.DefaultInitialBackoff, client.DefaultMaxBackoff))
+	defer source.Close()
+	target := connectTo(targetAddress)
+	defer target.Close()
+
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	replicator := client.NewReplicator(source, target, clientID, *selector, mapping)
+	if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					reportReplication(ctx, replicator)
+				}
+			}
+		}()
+	}
+	fmt.Printf("Mirroring %s to %s as %s (mapping in %s)\n", sourceAddress, targetAddress, clientID, mappingFile)
+	err = replicator.Run(ctx)
+	status, _ := replicator.Status(context.Background())
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "Mirror stopped after source sequence %d, run it again to resume: %s\n", status.SourceSequence, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Mirrored %d events, up to source sequence %d (target sequence %d)\n", status.Replicated, status.SourceSequence, status.TargetSequence)
+}
+
+func reportReplication(ctx context.Context, replicator *client.Replicator) {
+	status, err := replicator.Status(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Can't determine the lag: %s\n", err)
+		}
+		return
+	}
+	fmt.Printf("%s  source %d -> target %d  replicated %d  lag %d\n",
+		time.Now().Format("15:04:05"), status.SourceSequence, status.TargetSequence, status.Replicated, status.Lag)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ticker-es/client-go/config"
+	"github.com/ticker-es/client-go/support"
+)
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// executePing checks the connection to the server step by step, so the first failing step points at the problem.
+func executePing(cmd *cobra.Command, args []string) {
+	count, _ := cmd.Flags().GetInt("count")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if count < 1 {
+		exit(fmt.Errorf("invalid --count %d", count))
+	}
+	address := viper.GetString("connect")
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		exit(fmt.Errorf("invalid address %q: %w", address, err))
+	}
+	ctx, _ := support.CancelContextOnSignals(context.Background(), syscall.SIGINT)
+	fmt.Printf("PING %s\n", address)
+
+	start := time.Now()
+	dnsCtx, cancel := context.WithTimeout(ctx, timeout)
+	addresses, err := net.DefaultResolver.LookupHost(dnsCtx, host)
+	cancel()
+	if err != nil {
+		fail("dns", err)
+	}
+	report("dns", "resolved %s to %s in %s", host, strings.Join(addresses, ", "), since(start))
+
+	start = time.Now()
+	var conn net.Conn
+	for _, ip := range addresses {
+		if conn, err = net.DialTimeout("tcp", net.JoinHostPort(ip, port), timeout); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		fail("tcp", err)
+	}
+	report("tcp", "connected to %s in %s", conn.RemoteAddr(), since(start))
+
+	if viper.GetBool("insecure") {
+		report("tls", "skipped (--insecure)")
+		conn.Close()
+	} else {
+		pingTLS(conn, host, timeout)
+	}
+
+	cl := connect()
+	defer cl.Close()
+	var rtts []time.Duration
+	for i := 0; i < count; i++ {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		start = time.Now()
+		_, err := cl.ServerState(callCtx)
+		rtt := time.Since(start)
+		cancel()
+		if err != nil {
+			if i == 0 {
+				switch status.Code(err) {
+				case codes.Unauthenticated, codes.PermissionDenied:
+					fail("auth", err)
+				}
+			}
+			fail("rpc", err)
+		}
+		if i == 0 {
+			report("auth", "ok (%s)", credentialsInUse())
+		}
+		rtts = append(rtts, rtt)
+	}
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	report("rtt", "%d calls: min %s  p50 %s  p90 %s  p99 %s  max %s", count,
+		round(rtts[0]), round(percentile(rtts, 50)), round(percentile(rtts, 90)), round(percentile(rtts, 99)), round(rtts[len(rtts)-1]))
+}
+
+// pingTLS performs the handshake on the connection and reports what has been negotiated. The server certificate is
+// verified after the handshake, so its details can be reported even if it is not trusted.
+func pingTLS(conn net.Conn, host string, timeout time.Duration) {
+	cfg := config.TLSConfig(viper.GetString("ca_cert"), viper.GetString("client_cert"), viper.GetString("client_key"))
+	cfg.ServerName = host
+	cfg.InsecureSkipVerify = true
+	requested, sent := false, false
+	cfg.GetClientCertificate = func(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		requested = true
+		if len(cfg.Certificates) == 0 {
+			return &tls.Certificate{}, nil
+		}
+		sent = true
+		return &cfg.Certificates[0], nil
+	}
+	tlsConn := tls.Client(conn, cfg)
+	defer tlsConn.Close()
+	tlsConn.SetDeadline(time.Now().Add(timeout))
+	start := time.Now()
+	if err := tlsConn.Handshake(); err != nil {
+		fail("tls", err)
+	}
+	state := tlsConn.ConnectionState()
+	report("tls", "%s (%s) in %s", tlsVersions[state.Version], tls.CipherSuiteName(state.CipherSuite), since(start))
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		report("", "server certificate %s, issued by %s, %s", cert.Subject, cert.Issuer, expiry(cert.NotAfter))
+		intermediates := x509.NewCertPool()
+		for _, c := range state.PeerCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		_, err := cert.Verify(x509.VerifyOptions{Roots: cfg.RootCAs, DNSName: host, Intermediates: intermediates})
+		if err != nil {
+			report("", "server certificate NOT trusted: %s", err)
+		} else {
+			report("", "server certificate trusted")
+		}
+	}
+	switch {
+	case sent:
+		report("", "client certificate sent")
+	case requested:
+		report("", "client certificate requested by the server, but none configured")
+	default:
+		report("", "client certificate not requested")
+	}
+}
+
+func expiry(notAfter time.Time) string {
+	days := int(time.Until(notAfter).Hours() / 24)
+	if days < 0 {
+		return fmt.Sprintf("expired %s (%d days ago)", notAfter.Format("2006-01-02"), -days)
+	}
+	return fmt.Sprintf("expires %s (in %d days)", notAfter.Format("2006-01-02"), days)
+}
+
+func credentialsInUse() string {
+	var credentials []string
+	if viper.GetString("token") != "" {
+		credentials = append(credentials, "token")
+	}
+	if viper.GetString("client_cert") != "" && !viper.GetBool("insecure") {
+		credentials = append(credentials, "client certificate")
+	}
+	if len(credentials) == 0 {
+		return "anonymous"
+	}
+	return strings.Join(credentials, ", ")
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func report(step string, format string, args ...interface{}) {
+	fmt.Printf("%-5s %s\n", step, fmt.Sprintf(format, args...))
+}
+
+// fail reports the failed step and terminates the command.
+func fail(step string, err error) {
+	fmt.Printf("%-5s FAILED: %s\n", step, err)
+	os.Exit(1)
+}
+
+func since(start time.Time) time.Duration {
+	return round(time.Since(start))
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
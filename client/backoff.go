@@ -0,0 +1,38 @@
+package client
+
+import (
+	"time"
+)
+
+const (
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// Backoff computes exponentially growing delays between Initial and Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	next    time.Duration
+}
+
+// Next returns the delay to wait before the next attempt.
+func (b *Backoff) Next() time.Duration {
+	if b.next == 0 {
+		b.next = b.Initial
+		if b.next <= 0 {
+			b.next = DefaultInitialBackoff
+		}
+	}
+	delay := b.next
+	b.next *= 2
+	if b.Max > 0 && b.next > b.Max {
+		b.next = b.Max
+	}
+	return delay
+}
+
+// Reset starts over with the Initial delay.
+func (b *Backoff) Reset() {
+	b.next = 0
+}
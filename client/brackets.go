@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	es "github.com/ticker-es/client-go/eventstream/base"
+)
+
+// ResolveBracket turns a Bracket relative to the end of the stream (see es.Last) into an absolute one.
+func (s *Client) ResolveBracket(ctx context.Context, bracket es.Bracket) (es.Bracket, error) {
+	if !bracket.IsRelative() {
+		return bracket, nil
+	}
+	lastSequence, err := s.lastSequence(ctx)
+	if err != nil {
+		return bracket, err
+	}
+	return bracket.Resolve(lastSequence), nil
+}
+
+// ResolveTimeRange determines the Bracket of Events which occurred within the TimeRange, by binary search over the
+// whole stream.
+func (s *Client) ResolveTimeRange(ctx context.Context, r es.TimeRange) (es.Bracket, error) {
+	lastSequence, err := s.lastSequence(ctx)
+	if err != nil {
+		return es.Bracket{}, err
+	}
+	return r.Resolve(lastSequence, func(sequence int64) (time.Time, error) {
+		return s.occurredAt(ctx, sequence)
+	})
+}
+
+func (s *Client) lastSequence(ctx context.Context) (int64, error) {
+	state, err := s.ServerState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return state.EventCount, nil
+}
+
+func (s *Client) occurredAt(ctx context.Context, sequence int64) (time.Time, error) {
+	ev, err := s.fetch(ctx, sequence)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ev.OccurredAt.AsTime(), nil
+}
@@ -0,0 +1,58 @@
+package client_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("TimeRanges", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("resolves TimeRanges on the server's stream", func() {
+		start := time.Date(2021, 3, 1, 13, 0, 0, 0, time.UTC)
+		for i := 0; i < 12; i++ {
+			srv.Seed(base.Event{OccurredAt: start.Add(time.Duration(i) * 10 * time.Minute)})
+		}
+		bracket, err := srv.Client().ResolveTimeRange(context.Background(), base.TimeRange{Since: start.Add(time.Hour)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bracket).To(Equal(base.Range(7, 12)))
+	})
+})
+
+var _ = Describe("Brackets", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("streams the tail of the stream", func() {
+		for i := 0; i < 20; i++ {
+			srv.Seed(base.Event{Type: "created"})
+		}
+		bracket, _ := base.ParseBracket("-3:")
+		var sequences []int64
+		_, err := srv.Client().Stream(context.Background(), &base.Selector{}, &bracket, func(e *base.Event) error {
+			sequences = append(sequences, e.Sequence)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sequences).To(Equal([]int64{18, 19, 20}))
+	})
+})
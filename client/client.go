@@ -1,6 +1,7 @@
 package client
 
 import (
+	es "github.com/ticker-es/client-go/eventstream/base"
 	"github.com/ticker-es/client-go/rpc"
 	"google.golang.org/grpc"
 )
@@ -14,6 +15,9 @@ type Client struct {
 	maintenanceClient   rpc.MaintenanceClient
 	authenticationToken string
 	autoAcknowledge     bool
+	middlewares         []Middleware
+	clock               es.Clock
+	reconnect           *Backoff
 }
 
 type Option = func(c *Client)
@@ -21,6 +25,7 @@ type Option = func(c *Client)
 func NewClient(address string, opts ...Option) *Client {
 	cl := &Client{
 		address: address,
+		clock:   es.SystemClock,
 	}
 	for _, opt := range opts {
 		opt(cl)
@@ -29,7 +34,11 @@ func NewClient(address string, opts ...Option) *Client {
 }
 
 func (s *Client) Connect() error {
-	if conn, err := grpc.Dial(s.address, s.dialOptions...); err != nil {
+	dialOptions := append([]grpc.DialOption(nil), s.dialOptions...)
+	if s.authenticationToken != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(tokenCredentials{token: s.authenticationToken, insecure: s.insecure}))
+	}
+	if conn, err := grpc.Dial(s.address, dialOptions...); err != nil {
 		return err
 	} else {
 		s.connection = conn
@@ -38,3 +47,10 @@ func (s *Client) Connect() error {
 		return nil
 	}
 }
+
+func (s *Client) Close() error {
+	if s.connection == nil {
+		return nil
+	}
+	return s.connection.Close()
+}
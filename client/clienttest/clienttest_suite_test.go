@@ -0,0 +1,13 @@
+package clienttest
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestClientTest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ClientTest Suite")
+}
@@ -0,0 +1,61 @@
+package clienttest
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("RemoteEventStream", func() {
+	var servers []*Server
+
+	AfterEach(func() {
+		for _, srv := range servers {
+			srv.Close()
+		}
+		servers = nil
+	})
+
+	factory := func() base.EventStream {
+		srv := NewServer()
+		servers = append(servers, srv)
+		return client.NewRemoteEventStream(srv.Client())
+	}
+
+	base.EventStreamSampleGroup(factory)
+
+	It("reports unknown subscriptions on Acknowledge", func() {
+		Expect(factory().Acknowledge("unknown", 1)).To(Equal(base.ErrSubscriptionNotFound))
+	})
+
+	It("returns handler errors from Wait", func() {
+		w := base.NewWrapper(factory())
+		w.Emit()
+		failure := errors.New("failure")
+		sub, err := w.Stream().Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error {
+			return failure
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sub.Wait()).To(MatchError(failure.Error()))
+		Expect(sub.Active()).To(BeFalse())
+	})
+
+	It("tracks the last acknowledged sequence and forgets Subscriptions on Shutdown", func() {
+		w := base.NewWrapper(factory())
+		w.Emit()
+		w.Emit()
+		sub, _ := w.Stream().Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error { return nil })
+		Eventually(func() int64 {
+			sequence, _ := sub.LastAcknowledgedSequence()
+			return sequence
+		}).Should(Equal(int64(2)))
+		sub.Shutdown()
+		Expect(sub.Active()).To(BeFalse())
+		Expect(w.Stream().Subscriptions()).To(BeEmpty())
+	})
+})
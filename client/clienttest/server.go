@@ -0,0 +1,186 @@
+package clienttest
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/memory"
+	"github.com/ticker-es/client-go/server"
+)
+
+const bufferSize = 1024 * 1024
+
+// Method names usable with FailNext.
+const (
+	Emit             = "Emit"
+	Stream           = "Stream"
+	Listen           = "Listen"
+	Subscribe        = "Subscribe"
+	Acknowledge      = "Acknowledge"
+	GetServerState   = "GetServerState"
+	Shutdown         = "Shutdown"
+	GetSubscriptions = "GetSubscriptions"
+)
+
+// Server is an in-process ticker server for testing code that uses client.Client. It is backed by a
+// memory.EventStream, connected through an in-memory pipe and records every emitted Event and every acknowledgement.
+type Server struct {
+	stream   *recordingStream
+	server   *server.Server
+	listener *bufconn.Listener
+	mutex    sync.Mutex
+	failures map[string][]error
+	clients  []*client.Client
+}
+
+// NewServer starts a new Server. It has to be closed after use.
+func NewServer() *Server {
+	s := &Server{
+		stream: &recordingStream{
+			EventStream:      memory.NewEventStream(),
+			acknowledgements: make(map[string][]int64),
+		},
+		listener: bufconn.Listen(bufferSize),
+		failures: make(map[string][]error),
+	}
+	s.server = server.NewServer(s.stream, server.ServerOptions(
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	))
+	go s.server.Serve(s.listener)
+	return s
+}
+
+// Client returns a new Client which is already connected to the Server.
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	dialer := func(ctx context.Context, address string) (net.Conn, error) {
+		return s.listener.Dial()
+	}
+	cl := client.NewClient("bufnet", append([]client.Option{
+		client.DialOptions(grpc.WithContextDialer(dialer), grpc.WithInsecure()),
+	}, opts...)...)
+	if err := cl.Connect(); err != nil {
+		panic(err)
+	}
+	s.mutex.Lock()
+	s.clients = append(s.clients, cl)
+	s.mutex.Unlock()
+	return cl
+}
+
+// Seed stores the given Events without recording them as emitted.
+func (s *Server) Seed(events ...base.Event) {
+	for _, event := range events {
+		if _, err := s.stream.EventStream.Emit(&event); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Emitted returns all Events emitted by clients, in order.
+func (s *Server) Emitted() []*base.Event {
+	return s.stream.Emitted()
+}
+
+// Acknowledged returns all sequences acknowledged by the given persistent client, in order.
+func (s *Server) Acknowledged(persistentClientID string) []int64 {
+	return s.stream.Acknowledged(persistentClientID)
+}
+
+// EventStream returns the EventStream backing the Server.
+func (s *Server) EventStream() base.EventStream {
+	return s.stream.EventStream
+}
+
+// FailNext makes the next calls of the given method (e.g. Emit) fail with the given errors, one per call.
+func (s *Server) FailNext(method string, errs ...error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures[method] = append(s.failures[method], errs...)
+}
+
+// Close disconnects all Clients and stops the Server.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	clients := s.clients
+	s.clients = nil
+	s.mutex.Unlock()
+	for _, cl := range clients {
+		cl.Close()
+	}
+	s.server.Stop()
+}
+
+func (s *Server) failure(fullMethod string) error {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	errs := s.failures[method]
+	if len(errs) == 0 {
+		return nil
+	}
+	s.failures[method] = errs[1:]
+	return errs[0]
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.failure(info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.failure(info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// recordingStream records all Events emitted and all sequences acknowledged through it.
+type recordingStream struct {
+	*memory.EventStream
+	mutex            sync.Mutex
+	emitted          []*base.Event
+	acknowledgements map[string][]int64
+}
+
+func (s *recordingStream) Emit(event *base.Event) (int64, error) {
+	sequence, err := s.EventStream.Emit(event)
+	if err == nil {
+		s.mutex.Lock()
+		s.emitted = append(s.emitted, event.Clone())
+		s.mutex.Unlock()
+	}
+	return sequence, err
+}
+
+func (s *recordingStream) Acknowledge(persistentClientID string, sequence int64) error {
+	s.mutex.Lock()
+	s.acknowledgements[persistentClientID] = append(s.acknowledgements[persistentClientID], sequence)
+	s.mutex.Unlock()
+	return s.EventStream.Acknowledge(persistentClientID, sequence)
+}
+
+func (s *recordingStream) Emitted() []*base.Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	emitted := make([]*base.Event, len(s.emitted))
+	for i, event := range s.emitted {
+		emitted[i] = event.Clone()
+	}
+	return emitted
+}
+
+func (s *recordingStream) Acknowledged(persistentClientID string) []int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]int64(nil), s.acknowledgements[persistentClientID]...)
+}
@@ -0,0 +1,57 @@
+package clienttest
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Server", func() {
+	var srv *Server
+
+	BeforeEach(func() {
+		srv = NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("records emitted Events", func() {
+		cl := srv.Client()
+		ev, err := cl.Emit(context.Background(), base.Event{Aggregate: []string{"test", "1"}, Type: "created"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ev.Sequence).To(Equal(int64(1)))
+		Expect(srv.Emitted()).To(HaveLen(1))
+		Expect(srv.Emitted()[0].Type).To(Equal("created"))
+	})
+
+	It("streams seeded Events without recording them", func() {
+		srv.Seed(base.Event{Aggregate: []string{"test", "1"}}, base.Event{Aggregate: []string{"test", "2"}})
+		count, err := srv.Client().Stream(context.Background(), &base.Selector{}, &base.Bracket{}, func(e *base.Event) error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(2)))
+		Expect(srv.Emitted()).To(BeEmpty())
+	})
+
+	It("fails the next calls of a method", func() {
+		srv.FailNext(Emit, status.Error(codes.Unavailable, "down"))
+		cl := srv.Client()
+		_, err := cl.Emit(context.Background(), base.Event{Type: "created"})
+		Expect(status.Code(err)).To(Equal(codes.Unavailable))
+		_, err = cl.Emit(context.Background(), base.Event{Type: "created"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("records acknowledgements of subscriptions", func() {
+		srv.Seed(base.Event{Aggregate: []string{"test", "1"}}, base.Event{Aggregate: []string{"test", "2"}})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go srv.Client().Subscribe(ctx, "subscriber", &base.Selector{}, func(e *base.Event) error { return nil })
+		Eventually(func() []int64 { return srv.Acknowledged("subscriber") }).Should(Equal([]int64{1, 2}))
+	})
+})
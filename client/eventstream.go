@@ -5,14 +5,25 @@ import (
 	"errors"
 	"io"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	es "github.com/ticker-es/client-go/eventstream/base"
 	"github.com/ticker-es/client-go/rpc"
 )
 
 var ErrInvalidClientID = errors.New("invalid clientID")
 
+// Emit emits the Event and returns it with its assigned sequence. An unset OccurredAt is taken from the Client's Clock.
 func (s *Client) Emit(ctx context.Context, event es.Event) (es.Event, error) {
-	pub, err := s.eventStreamClient.Emit(ctx, rpc.EventToProto(&event))
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = s.clock.Now()
+	}
+	outgoing := event
+	if err := s.outgoing(ctx, &outgoing); err != nil {
+		return event, err
+	}
+	pub, err := s.eventStreamClient.Emit(ctx, rpc.EventToProto(&outgoing))
 	if err != nil {
 		return event, err
 	}
@@ -24,10 +35,52 @@ func (s *Client) Emit(ctx context.Context, event es.Event) (es.Event, error) {
 	}
 }
 
+// Get returns the Event with the given sequence, or es.ErrSequenceNotFound.
+func (s *Client) Get(ctx context.Context, sequence int64) (*es.Event, error) {
+	ev, err := s.fetch(ctx, sequence)
+	if err != nil {
+		return nil, err
+	}
+	return s.incoming(ctx, ev)
+}
+
+// fetch streams a one-element Bracket, as the server has no dedicated call for single Events.
+func (s *Client) fetch(ctx context.Context, sequence int64) (*rpc.Event, error) {
+	if sequence < 1 {
+		// the server would read Range(0, 0) as everything
+		return nil, es.ErrSequenceNotFound
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	bracket := es.Range(sequence, sequence)
+	stream, err := s.eventStreamClient.Stream(ctx, &rpc.StreamRequest{
+		Bracket:  rpc.BracketToProto(&bracket),
+		Selector: rpc.SelectorToProto(&es.Selector{}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ev, err := stream.Recv()
+	if err == io.EOF {
+		return nil, es.ErrSequenceNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// Stream delivers the Events in the Bracket, resolving Brackets relative to the end of the stream first. Selectors
+// the server can't express, like several combined with es.AnyOf, are requested as broadly as necessary and filtered
+// on this side, which preserves the global order.
 func (s *Client) Stream(ctx context.Context, selector *es.Selector, bracket *es.Bracket, handler es.EventHandler) (int64, error) {
+	resolved, err := s.ResolveBracket(ctx, *bracket)
+	if err != nil {
+		return 0, err
+	}
+	remote, matches := serverSide(selector)
 	req := &rpc.StreamRequest{
-		Bracket:  rpc.BracketToProto(bracket),
-		Selector: rpc.SelectorToProto(selector),
+		Bracket:  rpc.BracketToProto(&resolved),
+		Selector: rpc.SelectorToProto(remote),
 	}
 	stream, err := s.eventStreamClient.Stream(ctx, req)
 	if err != nil {
@@ -42,7 +95,13 @@ func (s *Client) Stream(ctx context.Context, selector *es.Selector, bracket *es.
 			return counter, err
 		}
 
-		event := rpc.ProtoToEvent(ev)
+		event, err := s.incoming(ctx, ev)
+		if err != nil {
+			return counter, err
+		}
+		if !matches(event) {
+			continue
+		}
 		if err := handler(event); err != nil {
 			return counter, err
 		}
@@ -51,44 +110,133 @@ func (s *Client) Stream(ctx context.Context, selector *es.Selector, bracket *es.
 	return counter, nil
 }
 
+func (s *Client) Listen(ctx context.Context, sel *es.Selector, handler es.EventHandler) error {
+	remote, matches := serverSide(sel)
+	req := &rpc.ListenRequest{
+		Selector: rpc.SelectorToProto(remote),
+	}
+	stream, err := s.eventStreamClient.Listen(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		event, err := s.incoming(ctx, ev)
+		if err != nil {
+			return err
+		}
+		if !matches(event) {
+			continue
+		}
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+}
+
+// Subscribe delivers all Events of the persistent subscription and acknowledges them once the handler succeeded. With
+// Reconnect, it re-attaches after the server became unavailable and continues after the last acknowledged Event.
 func (s *Client) Subscribe(ctx context.Context, clientID string, sel *es.Selector, handler es.EventHandler) error {
 	if clientID == "" {
 		return ErrInvalidClientID
 	}
+	if s.reconnect == nil {
+		_, err := s.subscribe(ctx, clientID, sel, handler)
+		return unwrapHandlerError(err)
+	}
+	backoff := *s.reconnect
+	for {
+		received, err := s.subscribe(ctx, clientID, sel, handler)
+		if _, ok := err.(handlerError); ok || status.Code(err) != codes.Unavailable || ctx.Err() != nil {
+			return unwrapHandlerError(err)
+		}
+		if received {
+			backoff.Reset()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(backoff.Next()):
+		}
+	}
+}
+
+// subscribe attaches to the subscription once and reports whether any Event was received.
+func (s *Client) subscribe(ctx context.Context, clientID string, sel *es.Selector, handler es.EventHandler) (bool, error) {
+	remote, matches := serverSide(sel)
 	req := &rpc.SubscriptionRequest{
 		PersistentClientId: clientID,
-		Selector:           rpc.SelectorToProto(sel),
+		Selector:           rpc.SelectorToProto(remote),
 	}
+	received := false
 	if sub, err := s.eventStreamClient.Subscribe(ctx, req); err == nil {
 		if ackStream, err := s.eventStreamClient.Acknowledge(ctx); err != nil {
-			return err
+			return received, err
 		} else {
 			defer ackStream.CloseSend()
 			for {
 				if ev, err := sub.Recv(); err == nil {
-					event := rpc.ProtoToEvent(ev)
-					if err := handler(event); err != nil {
-						// TODO Check whether to close connection
-						return err
+					received = true
+					event, err := s.incoming(ctx, ev)
+					if err != nil {
+						return received, err
+					}
+					// Events filtered on this side are acknowledged as well, so the subscription keeps advancing
+					if matches(event) {
+						if err := handler(event); err != nil {
+							// TODO Check whether to close connection
+							return received, handlerError{err}
+						}
 					}
 					ack := &rpc.Ack{
 						PersistentClientId: clientID,
 						Sequence:           event.Sequence,
 					}
 					if err := ackStream.Send(ack); err != nil {
-						return err
+						return received, err
 					}
 				} else {
 					if err == io.EOF {
 						// Server closed the connection
 						break
 					}
-					return err
+					return received, err
 				}
 			}
 		}
 	} else {
-		return err
+		return received, err
+	}
+	return received, nil
+}
+
+// serverSide returns the Selector to send to the server and the filter to apply to the Events it delivers, for
+// Selectors the server can't express exactly.
+func serverSide(sel *es.Selector) (*es.Selector, func(*es.Event) bool) {
+	remote, exact := sel.ServerSide()
+	if exact {
+		return &remote, func(*es.Event) bool { return true }
+	}
+	return &remote, sel.Matches
+}
+
+// handlerError marks errors of the EventHandler, which must not be mistaken for connection failures.
+type handlerError struct {
+	err error
+}
+
+func (e handlerError) Error() string {
+	return e.err.Error()
+}
+
+func unwrapHandlerError(err error) error {
+	if h, ok := err.(handlerError); ok {
+		return h.err
 	}
-	return nil
+	return err
 }
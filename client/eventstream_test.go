@@ -0,0 +1,94 @@
+package client_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Selectors", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("filters Selectors the server can't express on the client", func() {
+		srv.Seed(
+			base.Event{Aggregate: []string{"orders", "test", "1"}, Type: "created"},
+			base.Event{Aggregate: []string{"orders", "live", "2"}, Type: "created"},
+			base.Event{Aggregate: []string{"orders", "live", "2"}, Type: "deleted"},
+		)
+		sel, err := base.ParseSelector("!orders.test.**")
+		Expect(err).NotTo(HaveOccurred())
+		var types []string
+		count, err := srv.Client().Stream(context.Background(), sel, &base.Bracket{}, func(e *base.Event) error {
+			types = append(types, e.Aggregate[1]+"/"+e.Type)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(2)))
+		Expect(types).To(Equal([]string{"live/created", "live/deleted"}))
+
+		sel, _ = base.ParseSelector("orders.*/created|updated")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		delivered := make(chan int64, 3)
+		go srv.Client().Subscribe(ctx, "subscriber", sel, func(e *base.Event) error {
+			delivered <- e.Sequence
+			return nil
+		})
+		Eventually(func() []int64 { return srv.Acknowledged("subscriber") }).Should(Equal([]int64{1, 2, 3}))
+		Expect(delivered).To(HaveLen(2))
+	})
+	It("streams any of several Selectors in global order", func() {
+		srv.Seed(
+			base.Event{Aggregate: []string{"orders", "1"}, Type: "created"},
+			base.Event{Aggregate: []string{"payments", "1"}, Type: "captured"},
+			base.Event{Aggregate: []string{"payments", "1"}, Type: "refunded"},
+			base.Event{Aggregate: []string{"orders", "2"}, Type: "created"},
+		)
+		sel := base.AnyOf(
+			base.Select(base.SelectAggregate("orders", "*")),
+			base.Select(base.SelectAggregate("payments", "*"), base.SelectType("refunded")),
+		)
+		var sequences []int64
+		_, err := srv.Client().Stream(context.Background(), &sel, &base.Bracket{}, func(e *base.Event) error {
+			sequences = append(sequences, e.Sequence)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sequences).To(Equal([]int64{1, 3, 4}))
+	})
+})
+
+var _ = Describe("Get", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("gets single Events by sequence", func() {
+		srv.Seed(base.Event{Type: "created"}, base.Event{Type: "updated"})
+		cl := srv.Client()
+		ev, err := cl.Get(context.Background(), 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ev.Sequence).To(Equal(int64(2)))
+		Expect(ev.Type).To(Equal("updated"))
+		_, err = cl.Get(context.Background(), 3)
+		Expect(err).To(Equal(base.ErrSequenceNotFound))
+		_, err = cl.Get(context.Background(), 0)
+		Expect(err).To(Equal(base.ErrSequenceNotFound))
+	})
+})
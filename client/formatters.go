@@ -9,6 +9,8 @@ import (
 	"gopkg.in/workanator/go-ataman.v1"
 
 	es "github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/claimcheck"
+	"github.com/ticker-es/client-go/eventstream/upcast"
 )
 
 type Formatter func(w io.Writer, e *es.Event) error
@@ -20,6 +22,24 @@ func OmitPayload(f Formatter) Formatter {
 	}
 }
 
+func UpcastPayload(f Formatter, registry *upcast.Registry) Formatter {
+	return func(w io.Writer, e *es.Event) error {
+		if err := registry.Upcast(e); err != nil {
+			return err
+		}
+		return f(w, e)
+	}
+}
+
+func ResolveClaimChecks(f Formatter, claimCheck *claimcheck.ClaimCheck) Formatter {
+	return func(w io.Writer, e *es.Event) error {
+		if err := claimCheck.Resolve(e); err != nil {
+			return err
+		}
+		return f(w, e)
+	}
+}
+
 func JsonFormatter(pretty bool) Formatter {
 	return func(w io.Writer, e *es.Event) error {
 		enc := json.NewEncoder(w)
@@ -2,15 +2,75 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ticker-es/client-go/rpc"
 )
 
+// ShutdownPollInterval is how often AwaitShutdown checks whether the server is still there.
+const ShutdownPollInterval = 250 * time.Millisecond
+
+// PrintServerState prints the ServerState to stdout.
+//
+// Deprecated: Use ServerState, which returns the state and reports errors.
 func (s *Client) PrintServerState(ctx context.Context) {
-	if state, err := s.maintenanceClient.GetServerState(ctx, &emptypb.Empty{}); err == nil {
-		fmt.Printf("uptime: %5ds   |   active connections: %3d   |   events stored: %8d\n", state.Uptime, state.ConnectionCount, state.EventCount)
+	if state, err := s.ServerState(ctx); err == nil {
+		fmt.Printf("uptime: %5ds   |   active connections: %3d   |   events stored: %8d\n", int64(state.Uptime.Seconds()), state.ConnectionCount, state.EventCount)
 	} else {
 		fmt.Printf("Error occurred: %s\n", err)
 	}
 }
+
+// Shutdown asks the server to stop gracefully, forcing it down after the grace period (which the server only knows in
+// whole seconds, so it is rounded up). The server stops after the call has returned, see AwaitShutdown.
+func (s *Client) Shutdown(ctx context.Context, gracePeriod time.Duration) error {
+	if gracePeriod < 0 {
+		return errors.New("grace period must not be negative")
+	}
+	_, err := s.maintenanceClient.Shutdown(ctx, &rpc.ShutdownParameters{
+		GracePeriod: uint32(wholeSeconds(gracePeriod) / time.Second),
+	})
+	return err
+}
+
+// AwaitShutdown blocks until the server has gone away after Shutdown has been called with the same grace period.
+//
+// A stopping server refuses new calls right away, but keeps serving the streams already open until they have ended or
+// the grace period has passed. The server can't be asked whether it's still draining, so AwaitShutdown waits until it
+// refuses calls and the grace period, counted from now, has passed as well.
+func (s *Client) AwaitShutdown(ctx context.Context, gracePeriod time.Duration) error {
+	forced := s.clock.After(wholeSeconds(gracePeriod))
+	for {
+		_, err := s.maintenanceClient.GetServerState(ctx, &emptypb.Empty{})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if status.Code(err) == codes.Unavailable {
+			break
+		} else if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(ShutdownPollInterval):
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-forced:
+		return nil
+	}
+}
+
+// wholeSeconds rounds the grace period up to whole seconds like the server does.
+func wholeSeconds(gracePeriod time.Duration) time.Duration {
+	return (gracePeriod + time.Second - 1) / time.Second * time.Second
+}
@@ -0,0 +1,55 @@
+package client_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Shutdown", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("shuts the server down and waits until it is gone", func() {
+		cl := srv.Client()
+		Expect(cl.Shutdown(context.Background(), -time.Second)).NotTo(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Expect(cl.AwaitShutdown(ctx, 0)).To(Equal(context.DeadlineExceeded))
+
+		// An open stream keeps the server draining until the grace period has passed
+		received := make(chan int64, 100)
+		listening := make(chan error, 1)
+		go func() {
+			listening <- srv.Client().Listen(context.Background(), &base.Selector{}, func(e *base.Event) error {
+				received <- e.Sequence
+				return nil
+			})
+		}()
+		Eventually(func() int {
+			_, err := cl.Emit(context.Background(), base.Event{Aggregate: []string{"orders", "1"}, Type: "created"})
+			Expect(err).NotTo(HaveOccurred())
+			return len(received)
+		}).ShouldNot(BeZero())
+		start := time.Now()
+		Expect(cl.Shutdown(context.Background(), 500*time.Millisecond)).To(Succeed())
+		Expect(cl.AwaitShutdown(context.Background(), 500*time.Millisecond)).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Eventually(listening, 200*time.Millisecond).Should(Receive())
+		_, err := cl.Get(context.Background(), 1)
+		Expect(status.Code(err)).To(Equal(codes.Unavailable))
+	})
+})
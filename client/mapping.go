@@ -0,0 +1,163 @@
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// mappingRecordSize is the size of a pair of sequences in a FileSequenceMapping.
+const mappingRecordSize = 16
+
+// SequenceMapping keeps track of which source sequence a Replicator has stored under which target sequence.
+type SequenceMapping interface {
+	// Record stores the pair of sequences. Source sequences have to be recorded in ascending order.
+	Record(source, target int64) error
+	// Target returns the target sequence of the source sequence, or 0 if it has not been replicated.
+	Target(source int64) (int64, error)
+	// Last returns the last recorded pair, or zeros if there is none.
+	Last() (source, target int64, err error)
+}
+
+// MemorySequenceMapping keeps a SequenceMapping in memory, e.g. for tests.
+type MemorySequenceMapping struct {
+	mutex   sync.Mutex
+	sources []int64
+	targets []int64
+}
+
+func NewMemorySequenceMapping() *MemorySequenceMapping {
+	return &MemorySequenceMapping{}
+}
+
+func (m *MemorySequenceMapping) Record(source, target int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if n := len(m.sources); n > 0 && source <= m.sources[n-1] {
+		return fmt.Errorf("source sequence %d recorded after %d", source, m.sources[n-1])
+	}
+	m.sources = append(m.sources, source)
+	m.targets = append(m.targets, target)
+	return nil
+}
+
+func (m *MemorySequenceMapping) Target(source int64) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	i := sort.Search(len(m.sources), func(i int) bool { return m.sources[i] >= source })
+	if i < len(m.sources) && m.sources[i] == source {
+		return m.targets[i], nil
+	}
+	return 0, nil
+}
+
+func (m *MemorySequenceMapping) Last() (int64, int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if n := len(m.sources); n > 0 {
+		return m.sources[n-1], m.targets[n-1], nil
+	}
+	return 0, 0, nil
+}
+
+// FileSequenceMapping keeps a SequenceMapping in a file of fixed-size records, which is synced after every record and
+// searched on disk, so it doesn't grow in memory with the number of replicated Events.
+type FileSequenceMapping struct {
+	mutex      sync.Mutex
+	file       *os.File
+	count      int64
+	lastSource int64
+	lastTarget int64
+}
+
+// OpenSequenceMapping opens or creates the file. A record which has only been written partially is discarded.
+func OpenSequenceMapping(filename string) (*FileSequenceMapping, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	m := &FileSequenceMapping{
+		file:  file,
+		count: info.Size() / mappingRecordSize,
+	}
+	if info.Size()%mappingRecordSize != 0 {
+		if err := file.Truncate(m.count * mappingRecordSize); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if m.count > 0 {
+		if m.lastSource, m.lastTarget, err = m.read(m.count - 1); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *FileSequenceMapping) Record(source, target int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.count > 0 && source <= m.lastSource {
+		return fmt.Errorf("source sequence %d recorded after %d", source, m.lastSource)
+	}
+	var record [mappingRecordSize]byte
+	binary.BigEndian.PutUint64(record[:8], uint64(source))
+	binary.BigEndian.PutUint64(record[8:], uint64(target))
+	if _, err := m.file.WriteAt(record[:], m.count*mappingRecordSize); err != nil {
+		return err
+	}
+	if err := m.file.Sync(); err != nil {
+		return err
+	}
+	m.count++
+	m.lastSource, m.lastTarget = source, target
+	return nil
+}
+
+func (m *FileSequenceMapping) Target(source int64) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	low, high := int64(0), m.count
+	for low < high {
+		middle := low + (high-low)/2
+		s, t, err := m.read(middle)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case s == source:
+			return t, nil
+		case s < source:
+			low = middle + 1
+		default:
+			high = middle
+		}
+	}
+	return 0, nil
+}
+
+func (m *FileSequenceMapping) Last() (int64, int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.lastSource, m.lastTarget, nil
+}
+
+func (m *FileSequenceMapping) Close() error {
+	return m.file.Close()
+}
+
+func (m *FileSequenceMapping) read(index int64) (int64, int64, error) {
+	var record [mappingRecordSize]byte
+	if _, err := m.file.ReadAt(record[:], index*mappingRecordSize); err != nil {
+		return 0, 0, err
+	}
+	return int64(binary.BigEndian.Uint64(record[:8])), int64(binary.BigEndian.Uint64(record[8:])), nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// DefaultSampleInterval is how often the ticker metrics command samples the ServerState.
+const DefaultSampleInterval = 2 * time.Second
+
+// ServerState describes the state of the server at the time it was sampled.
+type ServerState struct {
+	// SampledAt is the time (according to the Client's Clock) the state was received.
+	SampledAt       time.Time
+	Uptime          time.Duration
+	ConnectionCount int
+	// EventCount is the number of Events stored, which is also the last sequence.
+	EventCount int64
+}
+
+// Sample is a ServerState together with the rates of change since the previous Sample.
+type Sample struct {
+	ServerState
+	EventsPerSecond      float64
+	ConnectionsPerSecond float64
+}
+
+// ServerState fetches the current state of the server.
+func (s *Client) ServerState(ctx context.Context) (ServerState, error) {
+	state, err := s.maintenanceClient.GetServerState(ctx, &emptypb.Empty{})
+	if err != nil {
+		return ServerState{}, err
+	}
+	return ServerState{
+		SampledAt:       s.clock.Now(),
+		Uptime:          time.Duration(state.Uptime) * time.Second,
+		ConnectionCount: int(state.ConnectionCount),
+		EventCount:      state.EventCount,
+	}, nil
+}
+
+// SampleServerState fetches the ServerState once per interval and passes it to the handler until the context is
+// cancelled or the handler returns an error. Failed samples are passed on with their error, so the handler decides
+// whether to give up; the rates of the next successful Sample are derived from the last successful one. The rates of
+// the first Sample, and of the first after the server restarted, are zero.
+func (s *Client) SampleServerState(ctx context.Context, interval time.Duration, handler func(sample Sample, err error) error) error {
+	var previous *ServerState
+	for {
+		state, err := s.ServerState(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var sample Sample
+		if err == nil {
+			sample = rates(previous, state)
+			previous = &state
+		}
+		if err := handler(sample, err); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(interval):
+		}
+	}
+}
+
+func rates(previous *ServerState, state ServerState) Sample {
+	sample := Sample{ServerState: state}
+	if previous == nil || state.Uptime < previous.Uptime {
+		return sample
+	}
+	elapsed := state.SampledAt.Sub(previous.SampledAt).Seconds()
+	if elapsed <= 0 {
+		return sample
+	}
+	sample.EventsPerSecond = float64(state.EventCount-previous.EventCount) / elapsed
+	sample.ConnectionsPerSecond = float64(state.ConnectionCount-previous.ConnectionCount) / elapsed
+	return sample
+}
@@ -0,0 +1,53 @@
+package client_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("ServerState", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("samples the server state with rates", func() {
+		srv.Seed(base.Event{Type: "created"}, base.Event{Type: "created"})
+		clock := base.NewFakeClock(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+		cl := srv.Client(client.Clock(clock))
+		state, err := cl.ServerState(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.EventCount).To(Equal(int64(2)))
+		Expect(state.ConnectionCount).To(BeNumerically(">=", 1))
+		Expect(state.SampledAt).To(Equal(clock.Now()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		samples := make(chan client.Sample, 10)
+		go cl.SampleServerState(ctx, 2*time.Second, func(sample client.Sample, err error) error {
+			samples <- sample
+			return err
+		})
+		var sample client.Sample
+		Eventually(samples).Should(Receive(&sample))
+		Expect(sample.EventsPerSecond).To(BeZero())
+		srv.Seed(base.Event{Type: "created"}, base.Event{Type: "created"}, base.Event{Type: "created"}, base.Event{Type: "created"})
+		Eventually(clock.Waiters).Should(Equal(1))
+		clock.Advance(2 * time.Second)
+		Eventually(samples).Should(Receive(&sample))
+		Expect(sample.EventCount).To(Equal(int64(6)))
+		Expect(sample.EventsPerSecond).To(Equal(2.0))
+		Expect(sample.ConnectionsPerSecond).To(BeZero())
+	})
+})
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+
+	es "github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/upcast"
+	"github.com/ticker-es/client-go/rpc"
+)
+
+// Middleware transforms Events on their way to and from the server. Outgoing is applied in the order the
+// Middlewares were registered, Incoming in reverse order.
+type Middleware interface {
+	// Outgoing is applied to every Event before it is emitted.
+	Outgoing(ctx context.Context, event *es.Event) error
+	// Incoming is applied to every Event received from the server before it is passed to the EventHandler.
+	Incoming(ctx context.Context, event *es.Event) error
+}
+
+func Use(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// Upcasters registers the given Registry to upcast all delivered Events to their latest schema version.
+func Upcasters(registry *upcast.Registry) Option {
+	return Use(&upcastMiddleware{registry: registry})
+}
+
+type upcastMiddleware struct {
+	registry *upcast.Registry
+}
+
+func (s *upcastMiddleware) Outgoing(ctx context.Context, event *es.Event) error {
+	return nil
+}
+
+func (s *upcastMiddleware) Incoming(ctx context.Context, event *es.Event) error {
+	return s.registry.Upcast(event)
+}
+
+func (s *Client) outgoing(ctx context.Context, event *es.Event) error {
+	if len(s.middlewares) == 0 {
+		return nil
+	}
+	payload := make(map[string]interface{}, len(event.Payload))
+	for key, value := range event.Payload {
+		payload[key] = value
+	}
+	event.Payload = payload
+	for _, m := range s.middlewares {
+		if err := m.Outgoing(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Client) incoming(ctx context.Context, ev *rpc.Event) (*es.Event, error) {
+	event := rpc.ProtoToEvent(ev)
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		if err := s.middlewares[i].Incoming(ctx, event); err != nil {
+			return event, err
+		}
+	}
+	return event, nil
+}
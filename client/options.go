@@ -1,8 +1,13 @@
 package client
 
 import (
+	"context"
+	"time"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+
+	es "github.com/ticker-es/client-go/eventstream/base"
 )
 
 func dialOptionWrapper(opts ...grpc.DialOption) Option {
@@ -11,18 +16,61 @@ func dialOptionWrapper(opts ...grpc.DialOption) Option {
 	}
 }
 
+// DialOptions passes additional options to grpc.Dial.
+func DialOptions(opts ...grpc.DialOption) Option {
+	return dialOptionWrapper(opts...)
+}
+
 func Credentials(cred credentials.TransportCredentials) Option {
 	return dialOptionWrapper(grpc.WithTransportCredentials(cred))
 }
 
+func Insecure() Option {
+	return func(c *Client) {
+		c.insecure = true
+		c.dialOptions = append(c.dialOptions, grpc.WithInsecure())
+	}
+}
+
+// AuthenticationToken sends the token as bearer token in the authorization metadata of every call.
 func AuthenticationToken(token string) Option {
 	return func(c *Client) {
 		c.authenticationToken = token
 	}
 }
 
+// tokenCredentials attach the authentication token to every call. Tokens are only sent over TLS, unless the Client
+// is Insecure.
+type tokenCredentials struct {
+	token    string
+	insecure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return !c.insecure
+}
+
 func AutoAcknowledge() Option {
 	return func(c *Client) {
 		c.autoAcknowledge = true
 	}
 }
+
+// Clock replaces the SystemClock, which timestamps emitted Events and times the Reconnect backoff.
+func Clock(clock es.Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// Reconnect makes Subscribe re-attach when the server becomes unavailable, waiting with exponential backoff from
+// initial up to max between attempts. The backoff is reset once Events are received again.
+func Reconnect(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.reconnect = &Backoff{Initial: initial, Max: max}
+	}
+}
@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Clock", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("timestamps emitted Events with the Client's Clock", func() {
+		clock := base.NewFakeClock(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+		_, err := srv.Client(client.Clock(clock)).Emit(context.Background(), base.Event{Type: "created"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(srv.Emitted()[0].OccurredAt).To(BeTemporally("==", clock.Now()))
+	})
+})
+
+var _ = Describe("Reconnect", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("re-attaches subscriptions with backoff", func() {
+		srv.Seed(base.Event{Aggregate: []string{"test", "1"}})
+		srv.FailNext(clienttest.Subscribe, status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"))
+		clock := base.NewFakeClock(time.Unix(0, 0))
+		cl := srv.Client(client.Clock(clock), client.Reconnect(time.Second, time.Minute))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go cl.Subscribe(ctx, "subscriber", &base.Selector{}, func(e *base.Event) error { return nil })
+		Eventually(clock.Waiters).Should(Equal(1))
+		clock.Advance(time.Second)
+		Eventually(clock.Waiters).Should(Equal(1))
+		clock.Advance(time.Second)
+		Consistently(func() []int64 { return srv.Acknowledged("subscriber") }).Should(BeEmpty())
+		clock.Advance(time.Second)
+		Eventually(func() []int64 { return srv.Acknowledged("subscriber") }).Should(Equal([]int64{1}))
+	})
+
+	It("does not re-attach after handler errors", func() {
+		srv.Seed(base.Event{Aggregate: []string{"test", "1"}})
+		cl := srv.Client(client.Reconnect(time.Millisecond, time.Millisecond))
+		err := cl.Subscribe(context.Background(), "subscriber", &base.Selector{}, func(e *base.Event) error {
+			return status.Error(codes.Unavailable, "handler")
+		})
+		Expect(err).To(MatchError(ContainSubstring("handler")))
+	})
+})
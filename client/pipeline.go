@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// DefaultInFlight is how many Events EmitAll emits concurrently by default.
+const DefaultInFlight = 16
+
+// ErrPredecessorFailed is reported by EmitAll for Events which have not been emitted because an earlier Event of the
+// same Aggregate failed.
+var ErrPredecessorFailed = errors.New("an earlier event of the same aggregate failed")
+
+// EmitAll emits the Events received from the channel until it is closed, with up to inFlight calls at a time. Events
+// of the same Aggregate are emitted one after another in the order received, while Events of different Aggregates
+// may end up in a different order unless inFlight is 1. Once an Event failed, the later Events of its Aggregate are
+// not emitted but reported with ErrPredecessorFailed.
+//
+// The callback receives every Event (with its Sequence, if it was emitted) and its position in the channel, counting
+// from 0. It is never called concurrently. EmitAll returns when all calls have finished, with the context's error if
+// it has been cancelled.
+func (s *Client) EmitAll(ctx context.Context, events <-chan base.Event, inFlight int, done func(index int, event base.Event, err error)) error {
+	if inFlight < 1 {
+		inFlight = 1
+	}
+	type chain struct {
+		done   chan struct{}
+		failed bool
+	}
+	var mutex sync.Mutex
+	// chains holds the last Event in flight per Aggregate, and the last one of every Aggregate which failed
+	chains := make(map[string]*chain)
+	slots := make(chan struct{}, inFlight)
+	var wg sync.WaitGroup
+	for index := 0; ; index++ {
+		var event base.Event
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				wg.Wait()
+				return ctx.Err()
+			}
+			event = e
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+		key := strings.Join(event.Aggregate, ".")
+		current := &chain{done: make(chan struct{})}
+		mutex.Lock()
+		previous := chains[key]
+		chains[key] = current
+		mutex.Unlock()
+		wg.Add(1)
+		go func(index int, event base.Event) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			var err error
+			if previous != nil {
+				<-previous.done
+				if previous.failed {
+					err = ErrPredecessorFailed
+				}
+			}
+			if err == nil {
+				event, err = s.Emit(ctx, event)
+			}
+			current.failed = err != nil
+			mutex.Lock()
+			if chains[key] == current && !current.failed {
+				delete(chains, key)
+			}
+			done(index, event, err)
+			mutex.Unlock()
+			close(current.done)
+		}(index, event)
+	}
+}
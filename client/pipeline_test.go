@@ -0,0 +1,73 @@
+package client_test
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("EmitAll", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("pipelines emits, keeping the order per Aggregate", func() {
+		events := make(chan base.Event)
+		go func() {
+			defer close(events)
+			for i := 0; i < 30; i++ {
+				events <- base.Event{Aggregate: []string{"orders", strconv.Itoa(i % 3)}, Type: "updated", Payload: map[string]interface{}{"n": i}}
+			}
+		}()
+		sequences := make([]int64, 30)
+		errs := make([]error, 30)
+		err := srv.Client().EmitAll(context.Background(), events, 4, func(index int, event base.Event, err error) {
+			sequences[index], errs[index] = event.Sequence, err
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for i := range errs {
+			Expect(errs[i]).NotTo(HaveOccurred())
+			Expect(sequences[i]).NotTo(BeZero())
+		}
+		order := map[string][]int{}
+		for _, e := range srv.Emitted() {
+			order[e.Aggregate[1]] = append(order[e.Aggregate[1]], int(e.Payload["n"].(float64)))
+		}
+		Expect(order).To(Equal(map[string][]int{
+			"0": {0, 3, 6, 9, 12, 15, 18, 21, 24, 27},
+			"1": {1, 4, 7, 10, 13, 16, 19, 22, 25, 28},
+			"2": {2, 5, 8, 11, 14, 17, 20, 23, 26, 29},
+		}))
+	})
+
+	It("does not emit Events after a failed one of the same Aggregate", func() {
+		srv.FailNext(clienttest.Emit, status.Error(codes.Internal, "failure"))
+		events := make(chan base.Event, 3)
+		events <- base.Event{Aggregate: []string{"orders", "1"}, Type: "created"}
+		events <- base.Event{Aggregate: []string{"orders", "2"}, Type: "created"}
+		events <- base.Event{Aggregate: []string{"orders", "1"}, Type: "updated"}
+		close(events)
+		errs := make([]error, 3)
+		err := srv.Client().EmitAll(context.Background(), events, 1, func(index int, event base.Event, err error) {
+			errs[index] = err
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Code(errs[0])).To(Equal(codes.Internal))
+		Expect(errs[1]).NotTo(HaveOccurred())
+		Expect(errs[2]).To(Equal(client.ErrPredecessorFailed))
+		Expect(srv.Emitted()).To(HaveLen(1))
+	})
+})
@@ -43,14 +43,24 @@ func ManualSuccession(cancel context.CancelFunc) func() {
 }
 
 func FixedDelay(delay int) func() {
+	return FixedDelayOn(base.SystemClock, delay)
+}
+
+// FixedDelayOn waits the given number of milliseconds on the Clock.
+func FixedDelayOn(clock base.Clock, delay int) func() {
 	return func() {
-		time.Sleep(time.Duration(delay) * time.Millisecond)
+		clock.Sleep(time.Duration(delay) * time.Millisecond)
 	}
 }
 
 func RandomDelay(delay int) func() {
+	return RandomDelayOn(base.SystemClock, delay)
+}
+
+// RandomDelayOn waits up to the given number of milliseconds on the Clock.
+func RandomDelayOn(clock base.Clock, delay int) func() {
 	return func() {
 		p := rand.Intn(delay)
-		time.Sleep(time.Duration(p) * time.Millisecond)
+		clock.Sleep(time.Duration(p) * time.Millisecond)
 	}
 }
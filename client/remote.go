@@ -0,0 +1,310 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	es "github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/rpc"
+)
+
+const (
+	DefaultRequestTimeout = 30 * time.Second
+	// reattachAttempts bounds how often a Subscription is retried while the server still holds the previous
+	// connection of the same persistent client.
+	reattachAttempts = 20
+	reattachDelay    = 50 * time.Millisecond
+)
+
+// Acknowledge acknowledges the sequence for the persistent client outside of Subscribe.
+func (s *Client) Acknowledge(ctx context.Context, clientID string, sequence int64) error {
+	if clientID == "" {
+		return ErrInvalidClientID
+	}
+	stream, err := s.eventStreamClient.Acknowledge(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&rpc.Ack{PersistentClientId: clientID, Sequence: sequence}); err != nil {
+		return err
+	}
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// RemoteEventStream implements es.EventStream on top of a connected Client, so code written against es.EventStream
+// runs against a remote server as well as against an in-memory stream.
+type RemoteEventStream struct {
+	client         *Client
+	requestTimeout time.Duration
+
+	mutex         sync.Mutex
+	subscriptions map[string]*RemoteSubscription
+}
+
+type RemoteOption = func(s *RemoteEventStream)
+
+// RequestTimeout limits calls of the es.EventStream interface which don't take a context.
+func RequestTimeout(timeout time.Duration) RemoteOption {
+	return func(s *RemoteEventStream) {
+		s.requestTimeout = timeout
+	}
+}
+
+func NewRemoteEventStream(client *Client, opts ...RemoteOption) *RemoteEventStream {
+	s := &RemoteEventStream{
+		client:         client,
+		requestTimeout: DefaultRequestTimeout,
+		subscriptions:  make(map[string]*RemoteSubscription),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *RemoteEventStream) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.requestTimeout)
+}
+
+func (s *RemoteEventStream) Emit(event *es.Event) (int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	emitted, err := s.client.Emit(ctx, *event)
+	if err != nil {
+		return 0, err
+	}
+	event.Sequence = emitted.Sequence
+	event.OccurredAt = emitted.OccurredAt
+	return emitted.Sequence, nil
+}
+
+// LastSequence returns the last sequence of the server, or 0 if it can't be reached.
+func (s *RemoteEventStream) LastSequence() int64 {
+	ctx, cancel := s.context()
+	defer cancel()
+	lastSequence, err := s.client.lastSequence(ctx)
+	if err != nil {
+		return 0
+	}
+	return lastSequence
+}
+
+func (s *RemoteEventStream) Get(sequence int64) (*es.Event, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.client.Get(ctx, sequence)
+}
+
+func (s *RemoteEventStream) Stream(ctx context.Context, sel es.Selector, bracket es.Bracket, handler es.EventHandler) error {
+	_, err := s.client.Stream(ctx, &sel, &bracket, handler)
+	return err
+}
+
+func (s *RemoteEventStream) Listen(ctx context.Context, sel es.Selector, handler es.EventHandler) error {
+	err := s.client.Listen(ctx, &sel, handler)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// Subscribe attaches to the persistent subscription in the background. Errors of the server or the handler are
+// returned by the Subscription's Wait.
+func (s *RemoteEventStream) Subscribe(ctx context.Context, persistentClientID string, sel es.Selector, handler es.EventHandler) (es.Subscription, error) {
+	if persistentClientID == "" {
+		return nil, ErrInvalidClientID
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sub, ok := s.subscriptions[persistentClientID]
+	if !ok {
+		sub = &RemoteSubscription{
+			stream:       s,
+			persistentID: persistentClientID,
+		}
+		s.subscriptions[persistentClientID] = sub
+	}
+	if err := sub.start(ctx, sel, handler); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *RemoteEventStream) Acknowledge(persistentClientID string, sequence int64) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	if err := s.client.Acknowledge(ctx, persistentClientID, sequence); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return es.ErrSubscriptionNotFound
+		}
+		return err
+	}
+	s.mutex.Lock()
+	sub, ok := s.subscriptions[persistentClientID]
+	s.mutex.Unlock()
+	if ok {
+		sub.acknowledged(sequence)
+	}
+	return nil
+}
+
+// Subscriptions returns the Subscriptions attached through this RemoteEventStream.
+func (s *RemoteEventStream) Subscriptions() []es.Subscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	subscriptions := make([]es.Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions
+}
+
+func (s *RemoteEventStream) removeSubscription(sub *RemoteSubscription) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.subscriptions[sub.persistentID] == sub {
+		delete(s.subscriptions, sub.persistentID)
+	}
+}
+
+// RemoteSubscription is an es.Subscription attached through a RemoteEventStream. Its state reflects what this side
+// has seen.
+type RemoteSubscription struct {
+	stream       *RemoteEventStream
+	persistentID string
+
+	mutex            sync.Mutex
+	selector         es.Selector
+	lastAcknowledged int64
+	active           bool
+	inactiveSince    time.Time
+	cancel           context.CancelFunc
+	done             chan struct{}
+	err              error
+}
+
+func (s *RemoteSubscription) start(ctx context.Context, sel es.Selector, handler es.EventHandler) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.active {
+		return es.ErrSubscriptionActive
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.selector = sel
+	s.active = true
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.err = nil
+	go s.run(ctx, sel, handler, s.done)
+	return nil
+}
+
+func (s *RemoteSubscription) run(ctx context.Context, sel es.Selector, handler es.EventHandler, done chan struct{}) {
+	client := s.stream.client
+	var err error
+	for attempt := 0; attempt < reattachAttempts; attempt++ {
+		err = client.Subscribe(ctx, s.persistentID, &sel, func(e *es.Event) error {
+			if err := handler(e); err != nil {
+				return err
+			}
+			s.acknowledged(e.Sequence)
+			return nil
+		})
+		if status.Code(err) != codes.AlreadyExists || ctx.Err() != nil {
+			break
+		}
+		err = es.ErrSubscriptionActive
+		select {
+		case <-ctx.Done():
+		case <-client.clock.After(reattachDelay):
+		}
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ctx.Err() == nil {
+		s.err = err
+	}
+	s.cancel()
+	s.active = false
+	s.inactiveSince = client.clock.Now()
+	close(done)
+}
+
+func (s *RemoteSubscription) acknowledged(sequence int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sequence > s.lastAcknowledged {
+		s.lastAcknowledged = sequence
+	}
+}
+
+func (s *RemoteSubscription) PersistentID() string {
+	return s.persistentID
+}
+
+func (s *RemoteSubscription) ActiveSelector() es.Selector {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.selector
+}
+
+// LastAcknowledgedSequence returns the last sequence handled or acknowledged through this RemoteSubscription. The
+// server can't report the acknowledgements of earlier connections, so a resumed Subscription reports 0 until the
+// first Event has been handled or acknowledged, even if the server resumes it after a later sequence.
+func (s *RemoteSubscription) LastAcknowledgedSequence() (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastAcknowledged, nil
+}
+
+func (s *RemoteSubscription) Acknowledge(sequence int64) error {
+	return s.stream.Acknowledge(s.persistentID, sequence)
+}
+
+func (s *RemoteSubscription) Active() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.active
+}
+
+func (s *RemoteSubscription) InactiveSince() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.inactiveSince
+}
+
+func (s *RemoteSubscription) Wait() error {
+	s.mutex.Lock()
+	done := s.done
+	s.mutex.Unlock()
+	if done == nil {
+		return nil
+	}
+	<-done
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// DropOuts is always 0, as the server doesn't report them.
+func (s *RemoteSubscription) DropOuts() int {
+	return 0
+}
+
+// Shutdown detaches the Subscription and forgets about it on this side. The server keeps its state, as there is no
+// call to remove it remotely.
+func (s *RemoteSubscription) Shutdown() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.Wait()
+	s.stream.removeSubscription(s)
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	es "github.com/ticker-es/client-go/eventstream/base"
+)
+
+// ReplicationStatus describes how far a Replicator has got.
+type ReplicationStatus struct {
+	// SourceSequence and TargetSequence are the last replicated pair of sequences.
+	SourceSequence int64 `json:"source_sequence"`
+	TargetSequence int64 `json:"target_sequence"`
+	// Replicated is the number of Events replicated since the Replicator has been started.
+	Replicated   int64     `json:"replicated"`
+	ReplicatedAt time.Time `json:"replicated_at"`
+	// Lag is the number of Events stored on the source after the last replicated one. With a Selector it includes
+	// Events which won't be replicated.
+	Lag int64 `json:"lag"`
+}
+
+// Replicator copies the Events of a persistent Subscription on the source server into the target server, recording
+// the sequences of each copy in a SequenceMapping.
+//
+// Events are acknowledged on the source only after they have been recorded, so a Replicator can be stopped and
+// started again at any time without losing or duplicating Events: Events delivered again are skipped by their source
+// sequence, and if the Replicator was stopped between emitting an Event and recording it, the copy is found on the
+// target by its content hash. This assumes that the Replicator is the only one emitting into the target.
+type Replicator struct {
+	source   *Client
+	target   *Client
+	clientID string
+	selector es.Selector
+	mapping  SequenceMapping
+
+	mutex  sync.Mutex
+	status ReplicationStatus
+}
+
+func NewReplicator(source, target *Client, clientID string, sel es.Selector, mapping SequenceMapping) *Replicator {
+	return &Replicator{
+		source:   source,
+		target:   target,
+		clientID: clientID,
+		selector: sel,
+		mapping:  mapping,
+	}
+}
+
+// Run replicates until the context is cancelled or an error occurs; the Subscription ends when the source server
+// closes it, unless the source Client reconnects.
+func (r *Replicator) Run(ctx context.Context) error {
+	lastSource, lastTarget, err := r.mapping.Last()
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	r.status.SourceSequence, r.status.TargetSequence = lastSource, lastTarget
+	r.mutex.Unlock()
+	unrecorded, err := r.unrecorded(ctx, lastTarget)
+	if err != nil {
+		return err
+	}
+	return r.source.Subscribe(ctx, r.clientID, &r.selector, func(e *es.Event) error {
+		if e.Sequence <= lastSource {
+			return nil
+		}
+		var target int64
+		hash := e.ContentHash()
+		if sequences := unrecorded[hash]; len(sequences) > 0 {
+			target = sequences[0]
+			unrecorded[hash] = sequences[1:]
+		} else {
+			event := *e
+			event.Sequence = 0
+			emitted, err := r.target.Emit(ctx, event)
+			if err != nil {
+				return err
+			}
+			target = emitted.Sequence
+		}
+		if err := r.mapping.Record(e.Sequence, target); err != nil {
+			return err
+		}
+		lastSource = e.Sequence
+		r.mutex.Lock()
+		r.status.SourceSequence, r.status.TargetSequence = e.Sequence, target
+		r.status.Replicated++
+		r.status.ReplicatedAt = r.source.clock.Now()
+		r.mutex.Unlock()
+		return nil
+	})
+}
+
+// Status returns the current ReplicationStatus, asking the source for the Lag.
+func (r *Replicator) Status(ctx context.Context) (ReplicationStatus, error) {
+	lastSequence, err := r.source.lastSequence(ctx)
+	if err != nil {
+		return ReplicationStatus{}, err
+	}
+	r.mutex.Lock()
+	status := r.status
+	r.mutex.Unlock()
+	if lag := lastSequence - status.SourceSequence; lag > 0 {
+		status.Lag = lag
+	}
+	return status, nil
+}
+
+// unrecorded returns the sequences of the Events on the target after the last recorded one by their content hash.
+func (r *Replicator) unrecorded(ctx context.Context, lastTarget int64) (map[string][]int64, error) {
+	unrecorded := make(map[string][]int64)
+	selector, bracket := es.Select(), es.From(lastTarget+1)
+	_, err := r.target.Stream(ctx, &selector, &bracket, func(e *es.Event) error {
+		hash := e.ContentHash()
+		unrecorded[hash] = append(unrecorded[hash], e.Sequence)
+		return nil
+	})
+	return unrecorded, err
+}
@@ -0,0 +1,86 @@
+package client_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Replicator", func() {
+	var srv *clienttest.Server
+
+	BeforeEach(func() {
+		srv = clienttest.NewServer()
+	})
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	It("replicates Events into another server without duplicates", func() {
+		target := clienttest.NewServer()
+		defer target.Close()
+		directory, err := ioutil.TempDir("", "mapping")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(directory)
+		filename := filepath.Join(directory, "mirror.mapping")
+		at := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		event := func(id int) base.Event {
+			return base.Event{Aggregate: []string{"orders", strconv.Itoa(id)}, Type: "created", OccurredAt: at, Payload: map[string]interface{}{"id": float64(id)}}
+		}
+		replicate := func(count int) {
+			mapping, err := client.OpenSequenceMapping(filename)
+			Expect(err).NotTo(HaveOccurred())
+			defer mapping.Close()
+			r := client.NewReplicator(srv.Client(), target.Client(), "mirror", base.Select(), mapping)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- r.Run(ctx) }()
+			Eventually(func() int64 {
+				status, err := r.Status(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				return status.SourceSequence
+			}).Should(Equal(int64(count)))
+			Eventually(func() []int64 { return srv.Acknowledged("mirror") }).Should(ContainElement(int64(count)))
+			cancel()
+			Eventually(done).Should(Receive())
+		}
+
+		srv.Seed(event(1), event(2))
+		// A copy of the third Event has been emitted before, but not recorded
+		srv.Seed(base.Event{Aggregate: []string{"other"}, Type: "ignored"}, event(3))
+		target.Seed(event(1), event(2))
+		mapping, err := client.OpenSequenceMapping(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.Record(1, 1)).To(Succeed())
+		Expect(mapping.Record(2, 2)).To(Succeed())
+		Expect(mapping.Close()).To(Succeed())
+		target.Seed(event(3))
+		replicate(4)
+		Expect(target.Emitted()).To(HaveLen(1))
+		Expect(target.Emitted()[0].Type).To(Equal("ignored"))
+
+		srv.Seed(event(5))
+		replicate(5)
+		Expect(target.Emitted()).To(HaveLen(2))
+		Expect(target.Emitted()[1].OccurredAt).To(Equal(at))
+
+		mapping, err = client.OpenSequenceMapping(filename)
+		Expect(err).NotTo(HaveOccurred())
+		defer mapping.Close()
+		for source, expected := range map[int64]int64{1: 1, 3: 4, 4: 3, 5: 5, 6: 0} {
+			sequence, err := mapping.Target(source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sequence).To(Equal(expected))
+		}
+	})
+})
@@ -1,6 +1,9 @@
 package config
 
-import c "github.com/mtrense/soil/config"
+import (
+	c "github.com/mtrense/soil/config"
+	"github.com/spf13/cobra"
+)
 
 func FlagConnect() c.Applicant {
 	return c.Flag("connect", c.Str("localhost:6677"), c.Abbr("c"), c.Description("Server to connect to"), c.Mandatory(), c.Persistent(), c.Env())
@@ -15,3 +18,23 @@ func FlagCerts() c.Applicant {
 		)
 	}
 }
+
+// selectorHelp explains the selector grammar in the description of every --selector flag.
+const selectorHelp = " (e.g. orders.*/created|updated, !orders.test.**; repeat to select any of several)"
+
+// FlagSelector defines the --selector flag, which may be given multiple times to select the Events matching any of
+// the selectors. The description only has to say what the selected Events are used for.
+func FlagSelector(description string) c.Applicant {
+	return c.WrapBuilderOption(func(cmd *cobra.Command) {
+		cmd.PersistentFlags().StringArrayP("selector", "s", []string{"/"}, description+selectorHelp)
+	})
+}
+
+// whereHelp shows an example expression in the description of every --where flag.
+const whereHelp = ` (e.g. payload.amount > 100 && payload.currency == "EUR")`
+
+// FlagWhere defines the --where flag, which filters the handled Events by an expression of the filter package. The
+// description only has to say what happens with the matching Events.
+func FlagWhere(description string) c.Applicant {
+	return c.Flag("where", c.Str(""), c.Abbr("w"), c.Description(description+whereHelp), c.Persistent())
+}
@@ -9,21 +9,34 @@ import (
 	"io/ioutil"
 )
 
-func Connect(connect, caCert, clientCert, clientKey string) *client.Client {
-	certificates := readClientCerts(clientCert, clientKey)
-	cfg := &tls.Config{
-		Certificates:     certificates,
-		RootCAs:          ReadCACerts(caCert),
-		VerifyConnection: verifyConnection,
+func Connect(connect, caCert, clientCert, clientKey string, opts ...client.Option) *client.Client {
+	cred := credentials.NewTLS(TLSConfig(caCert, clientCert, clientKey))
+	cl := client.NewClient(connect, append([]client.Option{client.Credentials(cred)}, opts...)...)
+	if err := cl.Connect(); err != nil {
+		panic(err)
 	}
-	cred := credentials.NewTLS(cfg)
-	cl := client.NewClient(connect, client.Credentials(cred))
+	return cl
+}
+
+// ConnectInsecure connects without TLS.
+func ConnectInsecure(connect string, opts ...client.Option) *client.Client {
+	cl := client.NewClient(connect, append([]client.Option{client.Insecure()}, opts...)...)
 	if err := cl.Connect(); err != nil {
 		panic(err)
 	}
 	return cl
 }
 
+// TLSConfig returns the configuration Connect uses to verify the server and to authenticate with the client
+// certificate.
+func TLSConfig(caCert, clientCert, clientKey string) *tls.Config {
+	return &tls.Config{
+		Certificates:     readClientCerts(clientCert, clientKey),
+		RootCAs:          ReadCACerts(caCert),
+		VerifyConnection: verifyConnection,
+	}
+}
+
 func readClientCerts(clientCert, clientKey string) []tls.Certificate {
 	var certificates []tls.Certificate
 	if cert, err := tls.LoadX509KeyPair(clientCert, clientKey); err == nil {
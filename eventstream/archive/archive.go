@@ -0,0 +1,516 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+const (
+	// ManifestVersion is the version of the Manifest format written by this package.
+	ManifestVersion = 1
+	// CheckpointEvents is after how many Events a Writer completes a checkpoint, so an export which has been killed
+	// can be resumed from there.
+	CheckpointEvents = 1000
+)
+
+const manifestSuffix = ".manifest.json"
+
+var (
+	// ErrManifestMismatch is returned when the part of an archive described by its Manifest has changed since, so
+	// it can't be trusted or resumed.
+	ErrManifestMismatch = errors.New("archive does not match its manifest")
+	// ErrOutOfOrder is returned when Events are not written in ascending order of their sequences.
+	ErrOutOfOrder = errors.New("events have to be written in ascending order")
+)
+
+// Compression of an archive. Resumed archives consist of several gzip members or zstd frames, which readers
+// decompress as one.
+type Compression string
+
+const (
+	None Compression = "none"
+	Gzip Compression = "gzip"
+	Zstd Compression = "zstd"
+)
+
+// CompressionFor picks the Compression by the extension of the file name (.gz, .zst or anything else).
+func CompressionFor(filename string) Compression {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gz", ".gzip":
+		return Gzip
+	case ".zst", ".zstd":
+		return Zstd
+	}
+	return None
+}
+
+// ParseCompression parses the name of a Compression.
+func ParseCompression(s string) (Compression, error) {
+	switch c := Compression(strings.ToLower(s)); c {
+	case None, Gzip, Zstd:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown compression %q", s)
+}
+
+// Manifest describes an archive: what has been requested, what it contains and the checksum of the archive file. A
+// Writer updates it on every checkpoint, so after a crash it describes the beginning of the archive up to Size.
+type Manifest struct {
+	Version     int           `json:"version"`
+	File        string        `json:"file"`
+	Compression Compression   `json:"compression"`
+	Selector    base.Selector `json:"selector"`
+	// RangeStart and RangeEnd are the requested Bracket; a RangeEnd of 0 is open.
+	RangeStart    int64     `json:"range_start"`
+	RangeEnd      int64     `json:"range_end"`
+	EventCount    int64     `json:"event_count"`
+	FirstSequence int64     `json:"first_sequence"`
+	LastSequence  int64     `json:"last_sequence"`
+	Size          int64     `json:"size"`
+	SHA256        string    `json:"sha256"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ManifestPath returns where the Manifest of the archive is kept.
+func ManifestPath(filename string) string {
+	return filename + manifestSuffix
+}
+
+// ReadManifest reads the Manifest of the archive.
+func ReadManifest(filename string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(ManifestPath(filename))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Bracket returns the requested Bracket.
+func (m Manifest) Bracket() base.Bracket {
+	if m.RangeEnd <= 0 {
+		return base.From(m.RangeStart)
+	}
+	return base.Range(m.RangeStart, m.RangeEnd)
+}
+
+// Remaining returns the part of the requested Bracket which comes after the last archived Event.
+func (m Manifest) Remaining() base.Bracket {
+	bracket := m.Bracket()
+	if m.LastSequence >= bracket.NextSequence {
+		bracket.NextSequence = m.LastSequence + 1
+	}
+	return bracket
+}
+
+func (m *Manifest) write(filename string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := ManifestPath(filename)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Writer writes Events as newline-delimited JSON into an archive and keeps its Manifest up to date. Every checkpoint
+// completes a gzip member or zstd frame, so the archive can be cut off after it.
+type Writer struct {
+	filename   string
+	file       *trackingWriter
+	compressor io.WriteCloser
+	buffer     *bufio.Writer
+	encoder    *json.Encoder
+	manifest   Manifest
+	// pending counts the Events written since the last checkpoint.
+	pending int
+}
+
+// Create starts a new archive, which must not exist yet.
+func Create(filename string, compression Compression, sel base.Selector, bracket base.Bracket) (*Writer, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	rangeEnd := bracket.LastSequence
+	if rangeEnd == math.MaxInt64 {
+		rangeEnd = 0
+	}
+	now := time.Now()
+	w := &Writer{
+		filename: filename,
+		file:     &trackingWriter{file: file, hash: sha256.New()},
+		manifest: Manifest{
+			Version:     ManifestVersion,
+			File:        filepath.Base(filename),
+			Compression: compression,
+			Selector:    sel,
+			RangeStart:  bracket.NextSequence,
+			RangeEnd:    rangeEnd,
+			CreatedAt:   now,
+		},
+	}
+	// The empty archive is a checkpoint as well, so an export killed before its first checkpoint can be resumed
+	if err = w.checkpoint(); err == nil {
+		err = w.startMember()
+	}
+	if err != nil {
+		// Without its Manifest the archive couldn't be resumed, so it must not block the next attempt
+		file.Close()
+		os.Remove(ManifestPath(filename))
+		os.Remove(filename)
+		return nil, err
+	}
+	return w, nil
+}
+
+// Resume continues an archive after the last Event of its last checkpoint. Whatever has been written after that
+// checkpoint, e.g. by an export which has been killed, is cut off and has to be written again. The archive up to the
+// checkpoint has to match its Manifest.
+func Resume(filename string) (*Writer, error) {
+	m, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filename, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := resume(filename, file, *m)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func resume(filename string, file *os.File, m Manifest) (*Writer, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	if info.Size() < m.Size {
+		return nil, fmt.Errorf("%s is shorter than its manifest: %w", filename, ErrManifestMismatch)
+	}
+	if _, err := io.CopyN(hash, file, m.Size); err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != m.SHA256 {
+		return nil, fmt.Errorf("%s: %w", filename, ErrManifestMismatch)
+	}
+	if info.Size() > m.Size {
+		if err := file.Truncate(m.Size); err != nil {
+			return nil, err
+		}
+	}
+	w := &Writer{
+		filename: filename,
+		file:     &trackingWriter{file: file, hash: hash, size: m.Size},
+		manifest: m,
+	}
+	if err := w.startMember(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// startMember starts a new gzip member or zstd frame after a checkpoint.
+func (w *Writer) startMember() error {
+	switch w.manifest.Compression {
+	case Gzip:
+		w.compressor = gzip.NewWriter(w.file)
+	case Zstd:
+		enc, err := zstd.NewWriter(w.file)
+		if err != nil {
+			return err
+		}
+		w.compressor = enc
+	default:
+		w.compressor = nopCloser{w.file}
+	}
+	w.buffer = bufio.NewWriter(w.compressor)
+	w.encoder = json.NewEncoder(w.buffer)
+	return nil
+}
+
+// Write appends the Event. It can be used as base.EventHandler.
+func (w *Writer) Write(e *base.Event) error {
+	if e.Sequence <= w.manifest.LastSequence {
+		return fmt.Errorf("sequence %d after %d: %w", e.Sequence, w.manifest.LastSequence, ErrOutOfOrder)
+	}
+	if err := w.encoder.Encode(e); err != nil {
+		return err
+	}
+	if w.manifest.FirstSequence == 0 {
+		w.manifest.FirstSequence = e.Sequence
+	}
+	w.manifest.LastSequence = e.Sequence
+	w.manifest.EventCount++
+	if w.pending++; w.pending >= CheckpointEvents {
+		return w.Checkpoint()
+	}
+	return nil
+}
+
+// Manifest returns the Manifest as it will be written on Close.
+func (w *Writer) Manifest() Manifest {
+	return w.manifest
+}
+
+// Checkpoint writes out all Events written so far and records them in the Manifest. Write does so every
+// CheckpointEvents Events.
+func (w *Writer) Checkpoint() error {
+	if err := w.finishMember(); err != nil {
+		return err
+	}
+	if err := w.checkpoint(); err != nil {
+		return err
+	}
+	return w.startMember()
+}
+
+// Close completes the archive and writes its Manifest, including the checksum of the whole archive.
+func (w *Writer) Close() error {
+	err := w.finishMember()
+	if err == nil {
+		err = w.checkpoint()
+	}
+	if cerr := w.file.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *Writer) finishMember() error {
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+	return w.compressor.Close()
+}
+
+// checkpoint syncs the archive and writes the Manifest for what has been written so far.
+func (w *Writer) checkpoint() error {
+	if err := w.file.file.Sync(); err != nil {
+		return err
+	}
+	w.manifest.Size = w.file.size
+	w.manifest.SHA256 = hex.EncodeToString(w.file.hash.Sum(nil))
+	w.manifest.UpdatedAt = time.Now()
+	w.pending = 0
+	return w.manifest.write(w.filename)
+}
+
+// RecordError reports a record which could not be decoded. Reading can continue with the next record.
+type RecordError struct {
+	Record int
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %s", e.Record, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads Events from newline-delimited JSON, as written by Writer, or from a JSON array, detecting the
+// Compression.
+type Reader struct {
+	closer       io.Closer
+	decompressor io.ReadCloser
+	lines        *bufio.Reader
+	decoder      *json.Decoder
+	record       int
+}
+
+// Open opens an archive for reading.
+func Open(filename string) (*Reader, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.closer = file
+	return r, nil
+}
+
+// NewReader reads Events from the reader, which is not closed by the Reader.
+func NewReader(reader io.Reader) (*Reader, error) {
+	r := &Reader{}
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(4)
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		r.decompressor = gz
+	case len(magic) == 4 && magic[0] == 0x28 && magic[1] == 0xb5 && magic[2] == 0x2f && magic[3] == 0xfd:
+		dec, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		r.decompressor = dec.IOReadCloser()
+	default:
+		r.decompressor = ioutil.NopCloser(buffered)
+	}
+	r.lines = bufio.NewReader(r.decompressor)
+	if first, err := firstNonSpace(r.lines); err == nil && first == '[' {
+		r.decoder = json.NewDecoder(r.lines)
+		if _, err := r.decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Next returns the next Event, or io.EOF at the end. Records which can't be decoded are reported as *RecordError;
+// in newline-delimited JSON reading can continue after them, in JSON arrays only if the record is valid JSON.
+func (r *Reader) Next() (*base.Event, error) {
+	if r.decoder != nil {
+		return r.nextElement()
+	}
+	for {
+		line, err := r.lines.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		r.record++
+		var e base.Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, &RecordError{Record: r.record, Err: err}
+		}
+		return &e, nil
+	}
+}
+
+func (r *Reader) nextElement() (*base.Event, error) {
+	if !r.decoder.More() {
+		if _, err := r.decoder.Token(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	r.record++
+	var e base.Event
+	if err := r.decoder.Decode(&e); err != nil {
+		if _, ok := err.(*json.UnmarshalTypeError); ok {
+			return nil, &RecordError{Record: r.record, Err: err}
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Record returns the number of the record last read, counting from 1.
+func (r *Reader) Record() int {
+	return r.record
+}
+
+func (r *Reader) Close() error {
+	err := r.decompressor.Close()
+	if r.closer != nil {
+		if cerr := r.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Verify checks the archive against its Manifest and returns the Manifest.
+func Verify(filename string) (*Manifest, error) {
+	m, err := ReadManifest(filename)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := checksum(filename)
+	if err != nil {
+		return nil, err
+	}
+	if sum != m.SHA256 {
+		return m, fmt.Errorf("%s: %w", filename, ErrManifestMismatch)
+	}
+	return m, nil
+}
+
+func firstNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+func checksum(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// trackingWriter writes to the archive file and keeps the size and checksum of everything written.
+type trackingWriter struct {
+	file *os.File
+	hash hash.Hash
+	size int64
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	n, err := t.file.Write(p)
+	t.hash.Write(p[:n])
+	t.size += int64(n)
+	return n, err
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
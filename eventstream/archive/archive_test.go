@@ -0,0 +1,268 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Archive", func() {
+	var directory string
+	orders := base.Select(base.SelectAggregate("orders"))
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "archive")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	event := func(sequence int64) *base.Event {
+		return &base.Event{
+			Sequence:   sequence,
+			Aggregate:  []string{"orders", "1"},
+			Type:       "created",
+			OccurredAt: time.Date(2021, 3, 1, 12, 0, int(sequence), 0, time.UTC),
+			Payload:    map[string]interface{}{"amount": float64(sequence)},
+		}
+	}
+	write := func(w *Writer, sequences ...int64) {
+		for _, sequence := range sequences {
+			Expect(w.Write(event(sequence))).To(Succeed())
+		}
+	}
+	readAll := func(filename string) []int64 {
+		r, err := Open(filename)
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+		var sequences []int64
+		for {
+			e, err := r.Next()
+			if err == io.EOF {
+				return sequences
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(e).To(Equal(event(e.Sequence)))
+			sequences = append(sequences, e.Sequence)
+		}
+	}
+	sha := func(filename string) string {
+		data, err := ioutil.ReadFile(filename)
+		Expect(err).NotTo(HaveOccurred())
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	DescribeTable("writes Events and reads them back",
+		func(name string, compression Compression) {
+			filename := filepath.Join(directory, name)
+			Expect(CompressionFor(filename)).To(Equal(compression))
+			w, err := Create(filename, compression, orders, base.Range(2, 10))
+			Expect(err).NotTo(HaveOccurred())
+			write(w, 2, 3, 5)
+			Expect(w.Close()).To(Succeed())
+			Expect(readAll(filename)).To(Equal([]int64{2, 3, 5}))
+
+			m, err := ReadManifest(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.File).To(Equal(name))
+			Expect(m.Compression).To(Equal(compression))
+			Expect(m.Selector.String()).To(Equal("orders/"))
+			Expect(m.Bracket()).To(Equal(base.Range(2, 10)))
+			Expect(m.EventCount).To(Equal(int64(3)))
+			Expect(m.FirstSequence).To(Equal(int64(2)))
+			Expect(m.LastSequence).To(Equal(int64(5)))
+			Expect(m.SHA256).To(Equal(sha(filename)))
+			Expect(m.Remaining()).To(Equal(base.Range(6, 10)))
+		},
+		Entry("uncompressed", "events.ndjson", None),
+		Entry("gzip", "events.ndjson.gz", Gzip),
+		Entry("zstd", "events.ndjson.zst", Zstd),
+	)
+
+	DescribeTable("resumes after the last Event",
+		func(compression Compression) {
+			filename := filepath.Join(directory, "events")
+			w, err := Create(filename, compression, orders, base.From(1))
+			Expect(err).NotTo(HaveOccurred())
+			write(w, 1, 2)
+			Expect(w.Close()).To(Succeed())
+
+			w, err = Resume(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Manifest().Remaining()).To(Equal(base.From(3)))
+			Expect(w.Write(event(2))).To(MatchError(ErrOutOfOrder))
+			write(w, 3, 4)
+			Expect(w.Close()).To(Succeed())
+
+			Expect(readAll(filename)).To(Equal([]int64{1, 2, 3, 4}))
+			m, err := ReadManifest(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.EventCount).To(Equal(int64(4)))
+			Expect(m.FirstSequence).To(Equal(int64(1)))
+			Expect(m.SHA256).To(Equal(sha(filename)))
+		},
+		Entry("uncompressed", None),
+		Entry("gzip", Gzip),
+		Entry("zstd", Zstd),
+	)
+
+	DescribeTable("resumes after the last checkpoint of a killed export",
+		func(compression Compression) {
+			filename := filepath.Join(directory, "events")
+			w, err := Create(filename, compression, orders, base.From(1))
+			Expect(err).NotTo(HaveOccurred())
+			write(w, 1, 2)
+			Expect(w.Checkpoint()).To(Succeed())
+			checkpoint, err := ioutil.ReadFile(ManifestPath(filename))
+			Expect(err).NotTo(HaveOccurred())
+			write(w, 3, 4, 5)
+			Expect(w.Close()).To(Succeed())
+
+			// The export has been killed in the middle of writing the Events after the checkpoint
+			Expect(ioutil.WriteFile(ManifestPath(filename), checkpoint, 0644)).To(Succeed())
+			m, err := ReadManifest(filename)
+			Expect(err).NotTo(HaveOccurred())
+			info, err := os.Stat(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Size()).To(BeNumerically(">", m.Size+1))
+			Expect(os.Truncate(filename, m.Size+(info.Size()-m.Size)/2)).To(Succeed())
+
+			w, err = Resume(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Manifest().Remaining()).To(Equal(base.From(3)))
+			write(w, 3, 4, 5)
+			Expect(w.Close()).To(Succeed())
+
+			Expect(readAll(filename)).To(Equal([]int64{1, 2, 3, 4, 5}))
+			m, err = ReadManifest(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.EventCount).To(Equal(int64(5)))
+			Expect(m.SHA256).To(Equal(sha(filename)))
+		},
+		Entry("uncompressed", None),
+		Entry("gzip", Gzip),
+		Entry("zstd", Zstd),
+	)
+
+	It("writes checkpoints while exporting", func() {
+		filename := filepath.Join(directory, "events.ndjson.gz")
+		w, err := Create(filename, Gzip, orders, base.From(1))
+		Expect(err).NotTo(HaveOccurred())
+		m, err := ReadManifest(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.EventCount).To(BeZero())
+		for sequence := int64(1); sequence <= CheckpointEvents+10; sequence++ {
+			write(w, sequence)
+		}
+		// Killed without closing the archive
+		Expect(w.file.file.Close()).To(Succeed())
+
+		w, err = Resume(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Manifest().EventCount).To(Equal(int64(CheckpointEvents)))
+		Expect(w.Manifest().Remaining()).To(Equal(base.From(CheckpointEvents + 1)))
+		Expect(w.Close()).To(Succeed())
+		Expect(readAll(filename)).To(HaveLen(CheckpointEvents))
+	})
+
+	It("refuses to resume archives which don't match their manifest", func() {
+		filename := filepath.Join(directory, "events.ndjson")
+		w, err := Create(filename, None, orders, base.All())
+		Expect(err).NotTo(HaveOccurred())
+		write(w, 1)
+		Expect(w.Close()).To(Succeed())
+		data, err := ioutil.ReadFile(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filename, []byte(strings.Replace(string(data), `"created"`, `"deleted"`, 1)), 0644)).To(Succeed())
+		_, err = Resume(filename)
+		Expect(err).To(MatchError(ErrManifestMismatch))
+
+		Expect(os.Truncate(filename, 10)).To(Succeed())
+		_, err = Resume(filename)
+		Expect(err).To(MatchError(ErrManifestMismatch))
+	})
+
+	It("does not overwrite existing archives", func() {
+		filename := filepath.Join(directory, "events.ndjson")
+		Expect(ioutil.WriteFile(filename, nil, 0644)).To(Succeed())
+		_, err := Create(filename, None, orders, base.All())
+		Expect(os.IsExist(err)).To(BeTrue())
+	})
+
+	It("removes archives whose Manifest can't be written", func() {
+		filename := filepath.Join(directory, "events.ndjson")
+		Expect(os.Mkdir(ManifestPath(filename)+".tmp", 0755)).To(Succeed())
+		_, err := Create(filename, None, orders, base.All())
+		Expect(err).To(HaveOccurred())
+		_, err = os.Stat(filename)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("parses compressions", func() {
+		Expect(ParseCompression("ZSTD")).To(Equal(Zstd))
+		_, err := ParseCompression("bzip2")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads JSON arrays", func() {
+		r, err := NewReader(strings.NewReader(` [{"sequence":1,"type":"created"}, {"sequence":2,"type":7}, {"sequence":3,"type":"deleted"}]`))
+		Expect(err).NotTo(HaveOccurred())
+		e, err := r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Type).To(Equal("created"))
+		_, err = r.Next()
+		var recordErr *RecordError
+		Expect(errors.As(err, &recordErr)).To(BeTrue())
+		Expect(recordErr.Record).To(Equal(2))
+		e, err = r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Type).To(Equal("deleted"))
+		_, err = r.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("continues after broken lines", func() {
+		r, err := NewReader(strings.NewReader("{\"sequence\":1}\n\n{\"sequ\n{\"sequence\":3}"))
+		Expect(err).NotTo(HaveOccurred())
+		e, err := r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Sequence).To(Equal(int64(1)))
+		_, err = r.Next()
+		Expect(err).To(MatchError(ContainSubstring("record 2")))
+		e, err = r.Next()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Sequence).To(Equal(int64(3)))
+		Expect(r.Record()).To(Equal(3))
+		_, err = r.Next()
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("verifies archives against their manifest", func() {
+		filename := filepath.Join(directory, "events.ndjson.gz")
+		w, err := Create(filename, Gzip, orders, base.All())
+		Expect(err).NotTo(HaveOccurred())
+		write(w, 1, 2)
+		Expect(w.Close()).To(Succeed())
+		m, err := Verify(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.EventCount).To(Equal(int64(2)))
+		Expect(ioutil.WriteFile(filename, []byte("{}"), 0644)).To(Succeed())
+		_, err = Verify(filename)
+		Expect(err).To(MatchError(ErrManifestMismatch))
+	})
+})
@@ -2,12 +2,17 @@ package base
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"time"
 )
 
 var (
-	ErrSequenceNotFound = errors.New("sequence not found")
+	ErrSequenceNotFound     = errors.New("sequence not found")
+	ErrSubscriptionActive   = errors.New("subscription is already active")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
 )
 
 type Event struct {
@@ -36,6 +41,7 @@ type Subscription interface {
 	PersistentID() string
 	// ActiveSelector returns the currently active Selector.
 	ActiveSelector() Selector
+	// LastAcknowledgedSequence returns the sequence of the last acknowledged Event.
 	LastAcknowledgedSequence() (int64, error)
 	Acknowledge(sequence int64) error
 	// Active returns whether this Subscription is currently active.
@@ -61,3 +67,47 @@ type SequenceStore interface {
 	Get(persistentClientID string) (int64, error)
 	Store(persistentClientID string, sequence int64) error
 }
+
+// Clone returns a deep copy of the Event, so it can be handed out without sharing its Aggregate or Payload.
+func (e *Event) Clone() *Event {
+	clone := *e
+	if e.Aggregate != nil {
+		clone.Aggregate = append([]string(nil), e.Aggregate...)
+	}
+	if e.Payload != nil {
+		clone.Payload = cloneValue(e.Payload).(map[string]interface{})
+	}
+	return &clone
+}
+
+// ContentHash identifies the content of the Event regardless of its Sequence: a SHA-256 over its Aggregate, Type,
+// OccurredAt and Payload.
+func (e *Event) ContentHash() string {
+	data, _ := json.Marshal(struct {
+		Aggregate  []string
+		Type       string
+		OccurredAt time.Time
+		Payload    map[string]interface{}
+	}{e.Aggregate, e.Type, e.OccurredAt.UTC(), e.Payload})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = cloneValue(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = cloneValue(value)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -1,6 +1,8 @@
 package base
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -86,4 +88,17 @@ var _ = Describe("Base", func() {
 		Expect(selAll2.Matches(ev3)).To(BeFalse())
 		Expect(selAll2.Matches(ev4)).To(BeFalse())
 	})
+
+	It("hashes the content of Events regardless of their sequence", func() {
+		occurredAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+		ev := &Event{Sequence: 1, Aggregate: []string{"orders", "1"}, Type: "created", OccurredAt: occurredAt, Payload: map[string]interface{}{"amount": 3, "currency": "EUR"}}
+		same := &Event{Sequence: 7, Aggregate: []string{"orders", "1"}, Type: "created", OccurredAt: occurredAt.In(time.FixedZone("CET", 3600)), Payload: map[string]interface{}{"currency": "EUR", "amount": 3.0}}
+		Expect(same.ContentHash()).To(Equal(ev.ContentHash()))
+		other := ev.Clone()
+		other.Payload["amount"] = 4
+		Expect(other.ContentHash()).NotTo(Equal(ev.ContentHash()))
+		other = ev.Clone()
+		other.OccurredAt = occurredAt.Add(time.Nanosecond)
+		Expect(other.ContentHash()).NotTo(Equal(ev.ContentHash()))
+	})
 })
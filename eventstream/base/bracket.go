@@ -1,13 +1,105 @@
 package base
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
 
+// DefaultHeadTailCount is the number of Events selected by the head and tail keywords.
+const DefaultHeadTailCount = 10
+
+// Bracket selects Events by sequence, including both bounds. A non-positive LastSequence is open.
 type Bracket struct {
 	NextSequence int64
 	LastSequence int64
+	// Tail, if positive, starts the Bracket this many Events before the end of the stream instead of at NextSequence.
+	Tail int64
+}
+
+// ParseBracket parses ranges like N (a single Event), N:M, N:, :M, -100: (the last 100 Events), head (the first 10)
+// or tail (the last 10). An empty range or : selects everything.
+func ParseBracket(s string) (Bracket, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "", ":":
+		return All(), nil
+	case "head":
+		return Range(1, DefaultHeadTailCount), nil
+	case "tail":
+		return Last(DefaultHeadTailCount), nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 2 {
+		return Bracket{}, fmt.Errorf("invalid range %q: expected at most one colon", s)
+	}
+	first, err := parseSequence(parts[0], true)
+	if err != nil {
+		return Bracket{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	if len(parts) == 1 {
+		if first < 0 {
+			return Last(-first), nil
+		}
+		return Range(first, first), nil
+	}
+	last, err := parseSequence(parts[1], false)
+	if err != nil {
+		return Bracket{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	bracket := Range(first, last)
+	if first <= 0 {
+		bracket.NextSequence = 1
+		bracket.Tail = -first
+	}
+	if last == 0 {
+		bracket.LastSequence = math.MaxInt64
+	}
+	if bracket.Tail == 0 && bracket.LastSequence < bracket.NextSequence {
+		return Bracket{}, fmt.Errorf("invalid range %q: end is before start", s)
+	}
+	return bracket, nil
+}
+
+// parseSequence parses one bound of a range; empty means open and is returned as 0.
+func parseSequence(s string, allowRelative bool) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	sequence, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a sequence", s)
+	}
+	switch {
+	case sequence == 0:
+		return 0, errors.New("sequences start at 1")
+	case sequence < 0 && !allowRelative:
+		return 0, errors.New("only the start can be relative to the end")
+	}
+	return sequence, nil
+}
+
+// IsRelative reports whether the Bracket starts relative to the end of the stream.
+func (s *Bracket) IsRelative() bool {
+	return s.Tail > 0
+}
+
+// Resolve returns the Bracket with a Tail turned into an absolute NextSequence.
+func (s Bracket) Resolve(lastSequence int64) Bracket {
+	if s.Tail > 0 {
+		s.NextSequence = lastSequence - s.Tail + 1
+		if s.NextSequence < 1 {
+			s.NextSequence = 1
+		}
+		s.Tail = 0
+	}
+	return s
 }
 
 func (s *Bracket) Sanitize(lastSequence int64) {
+	*s = s.Resolve(lastSequence)
 	if s.NextSequence < 1 {
 		s.NextSequence = 1
 	}
@@ -33,9 +125,37 @@ func Range(next, last int64) Bracket {
 	}
 }
 
+// Last selects the last count Events of the stream.
+func Last(count int64) Bracket {
+	return Bracket{
+		NextSequence: 1,
+		LastSequence: math.MaxInt64,
+		Tail:         count,
+	}
+}
+
 func From(next int64) Bracket {
 	return Bracket{
 		NextSequence: next,
 		LastSequence: math.MaxInt64,
 	}
 }
+
+// Intersect returns the Bracket covering only sequences in both (resolved) Brackets. Non-positive LastSequences are
+// open.
+func (s Bracket) Intersect(other Bracket) Bracket {
+	open := func(last int64) int64 {
+		if last <= 0 {
+			return math.MaxInt64
+		}
+		return last
+	}
+	bracket := Range(s.NextSequence, open(s.LastSequence))
+	if other.NextSequence > bracket.NextSequence {
+		bracket.NextSequence = other.NextSequence
+	}
+	if last := open(other.LastSequence); last < bracket.LastSequence {
+		bracket.LastSequence = last
+	}
+	return bracket
+}
@@ -0,0 +1,51 @@
+package base
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bracket", func() {
+	DescribeTable("parses ranges",
+		func(input string, expected Bracket) {
+			bracket, err := ParseBracket(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bracket).To(Equal(expected))
+		},
+		Entry("everything", "", All()),
+		Entry("just a colon", ":", All()),
+		Entry("single sequence", "5", Range(5, 5)),
+		Entry("closed range", "5:10", Range(5, 10)),
+		Entry("open end", "5:", From(5)),
+		Entry("open start", ":10", Range(1, 10)),
+		Entry("last Events", "-100:", Last(100)),
+		Entry("last Events without colon", "-100", Last(100)),
+		Entry("last Events up to", "-100:50", Bracket{NextSequence: 1, LastSequence: 50, Tail: 100}),
+		Entry("head", "head", Range(1, DefaultHeadTailCount)),
+		Entry("tail", "tail", Last(DefaultHeadTailCount)),
+	)
+
+	DescribeTable("rejects invalid ranges",
+		func(input string, message string) {
+			_, err := ParseBracket(input)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("garbage", "abc", `"abc" is not a sequence`),
+		Entry("two colons", "1:2:3", "at most one colon"),
+		Entry("zero", "0:5", "sequences start at 1"),
+		Entry("reversed", "10:5", "end is before start"),
+		Entry("relative end", "5:-1", "only the start can be relative"),
+	)
+
+	It("resolves Tails against the end of the stream", func() {
+		Expect(Last(10).Resolve(100)).To(Equal(Range(91, math.MaxInt64)))
+		Expect(Last(10).Resolve(5)).To(Equal(Range(1, math.MaxInt64)))
+		Expect(Range(3, 4).Resolve(100)).To(Equal(Range(3, 4)))
+		bracket := Last(10)
+		bracket.Sanitize(100)
+		Expect(bracket).To(Equal(Range(91, 100)))
+	})
+})
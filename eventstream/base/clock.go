@@ -0,0 +1,106 @@
+package base
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for everything that timestamps Events or waits, so tests can control it.
+type Clock interface {
+	Now() time.Time
+	// After returns a channel receiving the current time once the duration has passed.
+	After(duration time.Duration) <-chan time.Time
+	Sleep(duration time.Duration)
+}
+
+// SystemClock is the Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(duration time.Duration) <-chan time.Time {
+	return time.After(duration)
+}
+
+func (systemClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// FakeClock is a Clock which only moves when it is advanced. Everyone waiting on it is woken up as soon as their
+// deadline is reached.
+type FakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now,
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(duration time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ch := make(chan time.Time, 1)
+	if duration <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{deadline: c.now.Add(duration), ch: ch})
+	return ch
+}
+
+func (c *FakeClock) Sleep(duration time.Duration) {
+	<-c.After(duration)
+}
+
+// Advance moves the clock forward by the given duration.
+func (c *FakeClock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(c.now.Add(duration))
+}
+
+// Set moves the clock to the given time. Moving it backwards doesn't wake anyone up.
+func (c *FakeClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(now)
+}
+
+func (c *FakeClock) set(now time.Time) {
+	c.now = now
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if now.Before(w.deadline) {
+			waiters = append(waiters, w)
+		} else {
+			w.ch <- now
+		}
+	}
+	c.waiters = waiters
+}
+
+// Waiters returns how many callers of After or Sleep are waiting, to synchronize tests with the code under test.
+func (c *FakeClock) Waiters() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.waiters)
+}
@@ -0,0 +1,63 @@
+package base
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeClock", func() {
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	It("only moves when advanced", func() {
+		clock := NewFakeClock(start)
+		Expect(clock.Now()).To(Equal(start))
+		clock.Advance(time.Minute)
+		Expect(clock.Now()).To(Equal(start.Add(time.Minute)))
+		clock.Set(start)
+		Expect(clock.Now()).To(Equal(start))
+	})
+
+	It("wakes up waiters once their deadline is reached", func() {
+		clock := NewFakeClock(start)
+		short := clock.After(time.Second)
+		long := clock.After(time.Minute)
+		Expect(clock.Waiters()).To(Equal(2))
+		clock.Advance(30 * time.Second)
+		Expect(short).To(Receive(Equal(start.Add(30 * time.Second))))
+		Expect(long).NotTo(Receive())
+		clock.Advance(30 * time.Second)
+		Expect(long).To(Receive())
+		Expect(clock.Waiters()).To(BeZero())
+	})
+
+	It("returns immediately for durations in the past", func() {
+		clock := NewFakeClock(start)
+		Expect(clock.After(0)).To(Receive(Equal(start)))
+	})
+
+	It("blocks Sleep until advanced", func() {
+		clock := NewFakeClock(start)
+		done := make(chan struct{})
+		go func() {
+			clock.Sleep(time.Second)
+			close(done)
+		}()
+		Eventually(clock.Waiters).Should(Equal(1))
+		Consistently(done).ShouldNot(BeClosed())
+		clock.Advance(time.Second)
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("drives the OccurredAt of the EventStreamWrapper", func() {
+		clock := NewFakeClock(start)
+		wrapper := NewWrapperWithClock(nil, clock)
+		ev := &Event{}
+		wrapper.IncrBy(time.Hour)(ev)
+		Expect(ev.OccurredAt).To(Equal(start.Add(time.Hour)))
+		clock.Advance(time.Hour)
+		wrapper.After(time.Minute)(ev)
+		Expect(ev.OccurredAt).To(Equal(start.Add(2*time.Hour + time.Minute)))
+	})
+})
@@ -2,45 +2,112 @@ package base
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
+const (
+	// AnySegment matches exactly one aggregate segment (as does the empty string).
+	AnySegment = "*"
+	// AnyDepth matches any number (including zero) of aggregate segments.
+	AnyDepth = "**"
+	// Alternative separates alternatives within a type or segment, e.g. created|updated.
+	Alternative = "|"
+	// Negation prefixes a Selector to match all Events it would otherwise not match.
+	Negation = "!"
+	// Union separates Selectors of which any has to match, e.g. orders.*,payments.*/refunded.
+	Union = ","
+)
+
+// Selector selects Events by their Aggregate and Type. The Aggregate is matched as a prefix, so orders selects
+// orders.1 as well. Segments and the Type may be empty or * to match anything, end with * to match a prefix
+// (cust-*), or list alternatives (created|updated); the segment ** matches any number of segments.
 type Selector struct {
 	Aggregate []string
 	Type      string
+	// Negate inverts the Selector.
+	Negate bool
+	// Alternatives are matched in addition to the Selector itself (see AnyOf).
+	Alternatives []Selector
 }
 
+// ParseSelector parses selectors like orders.*/created|updated or !orders.test.**: dot-separated aggregate segments,
+// optionally followed by a slash and the type, optionally prefixed with ! to negate. Several selectors separated by
+// commas select the Events matching any of them.
 func ParseSelector(s string) (*Selector, error) {
+	if strings.Contains(s, Union) {
+		var selectors []Selector
+		for _, part := range strings.Split(s, Union) {
+			sel, err := ParseSelector(part)
+			if err != nil {
+				return nil, err
+			}
+			selectors = append(selectors, *sel)
+		}
+		sel := AnyOf(selectors...)
+		return &sel, nil
+	}
+	sel := &Selector{}
+	if strings.HasPrefix(s, Negation) {
+		sel.Negate = true
+		s = s[len(Negation):]
+	}
 	splitSelector := strings.Split(s, "/")
 	if len(splitSelector) > 2 {
 		return nil, errors.New("expected at most one slash")
 	}
-	aggregates := strings.Split(splitSelector[0], ".")
+	sel.Aggregate = strings.Split(splitSelector[0], ".")
+	for _, segment := range sel.Aggregate {
+		if segment == AnyDepth {
+			continue
+		}
+		if err := validatePattern(segment); err != nil {
+			return nil, fmt.Errorf("invalid aggregate segment %q: %w", segment, err)
+		}
+	}
 	if len(splitSelector) == 2 {
-		return &Selector{
-			Aggregate: aggregates,
-			Type:      splitSelector[1],
-		}, nil
-	} else {
-		return &Selector{
-			Aggregate: aggregates,
-			Type:      "",
-		}, nil
+		sel.Type = splitSelector[1]
+		if err := validatePattern(sel.Type); err != nil {
+			return nil, fmt.Errorf("invalid type %q: %w", sel.Type, err)
+		}
 	}
+	return sel, nil
 }
 
+func validatePattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	for _, alternative := range strings.Split(pattern, Alternative) {
+		switch {
+		case alternative == "":
+			return errors.New("empty alternative")
+		case strings.Contains(alternative, Negation):
+			return errors.New("negation is only allowed in front of the selector")
+		case strings.Contains(strings.TrimSuffix(alternative, AnySegment), AnySegment):
+			return errors.New("wildcards are only allowed at the end")
+		}
+	}
+	return nil
+}
+
+// IsComplete reports whether the Selector denotes a single Aggregate and Type, as needed to emit an Event.
 func (s *Selector) IsComplete() bool {
-	if s.Type == "" {
+	if s.Negate || len(s.Alternatives) > 0 || !isLiteral(s.Type) {
 		return false
 	}
 	for _, a := range s.Aggregate {
-		if a == "" {
+		if !isLiteral(a) {
 			return false
 		}
 	}
 	return true
 }
 
+func isLiteral(pattern string) bool {
+	return pattern != "" && !strings.ContainsAny(pattern, AnySegment+Alternative)
+}
+
 type SelectOption func(s *Selector)
 
 func Select(options ...SelectOption) Selector {
@@ -66,17 +133,163 @@ func SelectAggregate(agg ...string) SelectOption {
 	}
 }
 
+func SelectNegated() SelectOption {
+	return func(s *Selector) {
+		s.Negate = true
+	}
+}
+
+// AnyOf combines the Selectors into one which matches an Event if any of them does. Without Selectors it matches
+// everything, like Select().
+func AnyOf(selectors ...Selector) Selector {
+	if len(selectors) == 0 {
+		return Select()
+	}
+	sel := selectors[0]
+	sel.Alternatives = append(append([]Selector(nil), sel.Alternatives...), selectors[1:]...)
+	return sel
+}
+
 func (s *Selector) Matches(event *Event) bool {
-	if s.Type != "" && s.Type != event.Type {
+	if (matchPattern(s.Type, event.Type) && matchAggregate(s.Aggregate, event.Aggregate)) != s.Negate {
+		return true
+	}
+	for i := range s.Alternatives {
+		if s.Alternatives[i].Matches(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAggregate(pattern []string, aggregate []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == AnyDepth {
+		for i := 0; i <= len(aggregate); i++ {
+			if matchAggregate(pattern[1:], aggregate[i:]) {
+				return true
+			}
+		}
 		return false
 	}
-	if len(s.Aggregate) > len(event.Aggregate) {
+	if len(aggregate) == 0 {
 		return false
 	}
-	for index, token := range s.Aggregate {
-		if token != "" && event.Aggregate[index] != token {
+	return matchPattern(pattern[0], aggregate[0]) && matchAggregate(pattern[1:], aggregate[1:])
+}
+
+func matchPattern(pattern string, value string) bool {
+	if pattern == "" || pattern == AnySegment {
+		return true
+	}
+	for _, alternative := range strings.Split(pattern, Alternative) {
+		if prefix := strings.TrimSuffix(alternative, AnySegment); prefix != alternative {
+			if strings.HasPrefix(value, prefix) {
+				return true
+			}
+		} else if alternative == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerSide returns the broadest Selector a server understands (literal segments, empty wildcards and a single
+// literal Type) which still selects every Event s matches. The flag reports whether both select the same Events; if
+// not, the Events have to be filtered with s.Matches afterwards.
+func (s *Selector) ServerSide() (Selector, bool) {
+	sel, exact := s.serverSide()
+	for i := range s.Alternatives {
+		alternative, alternativeExact := s.Alternatives[i].ServerSide()
+		sel, exact = widen(sel, alternative), exact && alternativeExact && sameSelection(sel, alternative)
+	}
+	return sel, exact
+}
+
+// widen returns a server side Selector which selects everything both given ones select.
+func widen(a, b Selector) Selector {
+	sel := Selector{
+		Aggregate: make([]string, 0, len(a.Aggregate)),
+	}
+	for i := 0; i < len(a.Aggregate) && i < len(b.Aggregate); i++ {
+		if a.Aggregate[i] == b.Aggregate[i] {
+			sel.Aggregate = append(sel.Aggregate, a.Aggregate[i])
+		} else {
+			sel.Aggregate = append(sel.Aggregate, "")
+		}
+	}
+	if a.Type == b.Type {
+		sel.Type = a.Type
+	}
+	return sel
+}
+
+func sameSelection(a, b Selector) bool {
+	if a.Type != b.Type || len(a.Aggregate) != len(b.Aggregate) {
+		return false
+	}
+	for i := range a.Aggregate {
+		if a.Aggregate[i] != b.Aggregate[i] {
 			return false
 		}
 	}
 	return true
 }
+
+func (s *Selector) serverSide() (Selector, bool) {
+	if s.Negate {
+		return Select(), false
+	}
+	exact := true
+	sel := Selector{
+		Aggregate: make([]string, 0, len(s.Aggregate)),
+	}
+	for i, segment := range s.Aggregate {
+		if segment == AnyDepth {
+			exact = exact && i == len(s.Aggregate)-1
+			break
+		}
+		if segment == AnySegment || !isLiteral(segment) {
+			if segment != "" && segment != AnySegment {
+				exact = false
+			}
+			segment = ""
+		}
+		sel.Aggregate = append(sel.Aggregate, segment)
+	}
+	if s.Type != AnySegment && isLiteral(s.Type) {
+		sel.Type = s.Type
+	} else if s.Type != "" && s.Type != AnySegment {
+		exact = false
+	}
+	return sel, exact
+}
+
+// String formats the Selector in the syntax understood by ParseSelector.
+func (s *Selector) String() string {
+	str := strings.Join(s.Aggregate, ".") + "/" + s.Type
+	if s.Negate {
+		str = Negation + str
+	}
+	for i := range s.Alternatives {
+		str += Union + s.Alternatives[i].String()
+	}
+	return str
+}
+
+// MarshalText renders the Selector in the syntax understood by ParseSelector.
+func (s Selector) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses the Selector with ParseSelector.
+func (s *Selector) UnmarshalText(text []byte) error {
+	sel, err := ParseSelector(string(text))
+	if err != nil {
+		return err
+	}
+	*s = *sel
+	return nil
+}
@@ -0,0 +1,128 @@
+package base
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Selector", func() {
+	event := func(t string, agg ...string) *Event {
+		return &Event{Aggregate: agg, Type: t}
+	}
+
+	DescribeTable("matches Events",
+		func(selector string, ev *Event, matches bool) {
+			sel, err := ParseSelector(selector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sel.Matches(ev)).To(Equal(matches))
+		},
+		Entry("catch-all", "/", event("created", "orders", "1"), true),
+		Entry("aggregate prefix", "orders", event("created", "orders", "1"), true),
+		Entry("empty segment", "orders./created", event("created", "orders", "1"), true),
+		Entry("single segment wildcard", "orders.*/created", event("created", "orders", "1"), true),
+		Entry("single segment wildcard needs a segment", "orders.*/created", event("created", "orders"), false),
+		Entry("any depth", "tenants.**.invoices", event("created", "tenants", "a", "b", "invoices", "1"), true),
+		Entry("any depth matches zero segments", "tenants.**.invoices", event("created", "tenants", "invoices"), true),
+		Entry("any depth requires the rest", "tenants.**.invoices", event("created", "tenants", "a", "orders"), false),
+		Entry("segment prefix", "customers.cust-*", event("created", "customers", "cust-42"), true),
+		Entry("segment prefix mismatch", "customers.cust-*", event("created", "customers", "vendor-42"), false),
+		Entry("type alternatives", "orders.*/created|updated", event("updated", "orders", "1"), true),
+		Entry("type alternatives mismatch", "orders.*/created|updated", event("deleted", "orders", "1"), false),
+		Entry("type prefix", "/order*", event("ordered", "orders", "1"), true),
+		Entry("segment alternatives", "orders|invoices", event("created", "invoices", "1"), true),
+		Entry("negation", "!orders.test.**", event("created", "orders", "test", "1"), false),
+		Entry("negation of non-matching", "!orders.test.**", event("created", "orders", "live", "1"), true),
+	)
+
+	DescribeTable("rejects invalid selectors",
+		func(selector string) {
+			_, err := ParseSelector(selector)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("two slashes", "a/b/c"),
+		Entry("inner wildcard", "or*ers"),
+		Entry("inner negation", "orders.!test"),
+		Entry("empty alternative", "/created||updated"),
+		Entry("wildcard within any depth", "orders.***"),
+	)
+
+	It("is complete only without wildcards", func() {
+		for selector, complete := range map[string]bool{
+			"orders.1/created":         true,
+			"orders./created":          false,
+			"orders.*/created":         false,
+			"orders.1/created|updated": false,
+			"!orders.1/created":        false,
+		} {
+			sel, _ := ParseSelector(selector)
+			Expect(sel.IsComplete()).To(Equal(complete), selector)
+		}
+	})
+
+	DescribeTable("reduces to what a server can express",
+		func(selector string, aggregate []string, t string, exact bool) {
+			sel, err := ParseSelector(selector)
+			Expect(err).NotTo(HaveOccurred())
+			remote, isExact := sel.ServerSide()
+			Expect(remote.Aggregate).To(Equal(aggregate))
+			Expect(remote.Type).To(Equal(t))
+			Expect(remote.Negate).To(BeFalse())
+			Expect(isExact).To(Equal(exact))
+		},
+		Entry("plain", "orders.1/created", []string{"orders", "1"}, "created", true),
+		Entry("wildcards", "orders.*./*", []string{"orders", "", ""}, "", true),
+		Entry("trailing any depth", "orders.**", []string{"orders"}, "", true),
+		Entry("inner any depth", "tenants.**.invoices/created", []string{"tenants"}, "created", false),
+		Entry("prefix", "customers.cust-*.orders", []string{"customers", "", "orders"}, "", false),
+		Entry("alternatives", "orders/created|updated", []string{"orders"}, "", false),
+		Entry("negation", "!orders", []string{}, "", false),
+	)
+
+	It("formats in the parseable syntax", func() {
+		sel, _ := ParseSelector("!orders.*/created|updated")
+		Expect(sel.String()).To(Equal("!orders.*/created|updated"))
+	})
+
+	It("marshals to and from JSON in the parseable syntax", func() {
+		sel, _ := ParseSelector("!orders.*/created|updated")
+		data, err := json.Marshal(struct{ Selector Selector }{*sel})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"Selector":"!orders.*/created|updated"}`))
+		var decoded struct{ Selector Selector }
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded.Selector).To(Equal(*sel))
+		Expect(json.Unmarshal([]byte(`{"Selector":"a/b/c"}`), &decoded)).NotTo(Succeed())
+	})
+
+	It("matches any of several Selectors", func() {
+		sel, err := ParseSelector("orders.*,payments.*/refunded")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sel.Alternatives).To(HaveLen(1))
+		Expect(sel.Matches(event("created", "orders", "1"))).To(BeTrue())
+		Expect(sel.Matches(event("refunded", "payments", "1"))).To(BeTrue())
+		Expect(sel.Matches(event("captured", "payments", "1"))).To(BeFalse())
+		Expect(sel.String()).To(Equal("orders.*/,payments.*/refunded"))
+
+		everything := AnyOf()
+		Expect(everything.Matches(event("created", "orders", "1"))).To(BeTrue())
+	})
+
+	It("widens several Selectors to one a server can express", func() {
+		sel := AnyOf(
+			Select(SelectAggregate("orders", "1"), SelectType("created")),
+			Select(SelectAggregate("orders", "2", "items"), SelectType("created")),
+		)
+		remote, exact := sel.ServerSide()
+		Expect(remote.Aggregate).To(Equal([]string{"orders", ""}))
+		Expect(remote.Type).To(Equal("created"))
+		Expect(exact).To(BeFalse())
+
+		sel = AnyOf(Select(SelectAggregate("orders")), Select(SelectAggregate("orders")))
+		remote, exact = sel.ServerSide()
+		Expect(remote.Aggregate).To(Equal([]string{"orders"}))
+		Expect(exact).To(BeTrue())
+	})
+})
@@ -2,6 +2,7 @@ package base
 
 import (
 	"context"
+	"fmt"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -213,6 +214,7 @@ func EventStreamSampleGroup(factory func() EventStream) {
 		w.Emit(w.Type("second"), w.Agg("test", "1"))
 		w.Emit(w.Type("second"), w.Agg("test", "2"))
 		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
 		// Re-attach subscription
 		sub, _ = w.Stream().Subscribe(ctx, "test", Select(), func(e *Event) error {
 			counter++
@@ -223,3 +225,48 @@ func EventStreamSampleGroup(factory func() EventStream) {
 	})
 
 }
+
+func SequenceStoreSampleGroup(factory func() SequenceStore) {
+	It("returns 0 for unknown clients", func() {
+		s := factory()
+		Expect(s.Get("unknown")).To(Equal(int64(0)))
+	})
+
+	It("returns the stored sequence", func() {
+		s := factory()
+		Expect(s.Store("test", 5)).To(Succeed())
+		Expect(s.Get("test")).To(Equal(int64(5)))
+		Expect(s.Store("test", 7)).To(Succeed())
+		Expect(s.Get("test")).To(Equal(int64(7)))
+	})
+
+	It("keeps sequences of different clients apart", func() {
+		s := factory()
+		Expect(s.Store("test1", 1)).To(Succeed())
+		Expect(s.Store("test2", 2)).To(Succeed())
+		Expect(s.Store("test/3", 3)).To(Succeed())
+		Expect(s.Get("test1")).To(Equal(int64(1)))
+		Expect(s.Get("test2")).To(Equal(int64(2)))
+		Expect(s.Get("test/3")).To(Equal(int64(3)))
+	})
+
+	It("handles concurrent clients", func() {
+		s := factory()
+		done := make(chan error)
+		for i := 0; i < 10; i++ {
+			go func(clientID string) {
+				var err error
+				for sequence := int64(1); sequence <= 10 && err == nil; sequence++ {
+					err = s.Store(clientID, sequence)
+				}
+				done <- err
+			}(fmt.Sprintf("client-%d", i))
+		}
+		for i := 0; i < 10; i++ {
+			Expect(<-done).To(Succeed())
+		}
+		for i := 0; i < 10; i++ {
+			Expect(s.Get(fmt.Sprintf("client-%d", i))).To(Equal(int64(10)))
+		}
+	})
+}
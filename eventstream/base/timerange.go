@@ -0,0 +1,93 @@
+package base
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TimeRange selects Events by their OccurredAt, including both bounds. A zero bound leaves that side open.
+type TimeRange struct {
+	Since time.Time
+	Until time.Time
+}
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04", "2006-01-02"}
+
+var timeOfDayLayouts = []string{"15:04:05", "15:04"}
+
+// ParseTime parses an absolute time (RFC3339, 2006-01-02T15:04 or 2006-01-02), a time of day today (15:04 or
+// 15:04:05), "now" or a duration relative to now (-2h, -30m). Times without a zone are in now's location.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "now" {
+		return now, nil
+	}
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		duration, err := time.ParseDuration(s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative time %q: %w", s, err)
+		}
+		return now.Add(duration), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range timeOfDayLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			year, month, day := now.Date()
+			return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected RFC3339, a time of day, now or a relative duration like -2h", s)
+}
+
+// Resolve determines the Bracket of Events within the TimeRange by binary search, given the last sequence of the
+// stream and a function returning the OccurredAt of a sequence. It relies on OccurredAt growing with the sequence.
+func (r TimeRange) Resolve(lastSequence int64, occurredAt func(sequence int64) (time.Time, error)) (Bracket, error) {
+	var searchErr error
+	// search returns the first sequence for which the predicate holds, or lastSequence+1
+	search := func(predicate func(t time.Time) bool) int64 {
+		index := sort.Search(int(lastSequence), func(i int) bool {
+			if searchErr != nil {
+				return true
+			}
+			t, err := occurredAt(int64(i) + 1)
+			if err != nil {
+				searchErr = fmt.Errorf("reading sequence %d: %w", i+1, err)
+				return true
+			}
+			return predicate(t)
+		})
+		return int64(index) + 1
+	}
+	bracket := Range(1, lastSequence)
+	if !r.Since.IsZero() {
+		bracket.NextSequence = search(func(t time.Time) bool { return !t.Before(r.Since) })
+	}
+	if !r.Until.IsZero() {
+		bracket.LastSequence = search(func(t time.Time) bool { return t.After(r.Until) }) - 1
+	}
+	if searchErr != nil {
+		return Bracket{}, searchErr
+	}
+	if bracket.LastSequence < bracket.NextSequence {
+		// an empty Bracket which Sanitize doesn't widen
+		return Range(lastSequence+1, lastSequence), nil
+	}
+	return bracket, nil
+}
+
+// ResolveTimeRange resolves the TimeRange to a Bracket by looking up Events in the EventStream.
+func ResolveTimeRange(stream EventStream, r TimeRange) (Bracket, error) {
+	return r.Resolve(stream.LastSequence(), func(sequence int64) (time.Time, error) {
+		event, err := stream.Get(sequence)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return event.OccurredAt, nil
+	})
+}
@@ -0,0 +1,87 @@
+package base
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeRange", func() {
+	now := time.Date(2021, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	It("parses absolute and relative times", func() {
+		for input, expected := range map[string]time.Time{
+			"2021-02-28T14:00:00Z":      time.Date(2021, 2, 28, 14, 0, 0, 0, time.UTC),
+			"2021-02-28T14:00:00+01:00": time.Date(2021, 2, 28, 13, 0, 0, 0, time.UTC),
+			"2021-02-28T14:00":          time.Date(2021, 2, 28, 14, 0, 0, 0, time.UTC),
+			"2021-02-28":                time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC),
+			"14:30":                     time.Date(2021, 3, 1, 14, 30, 0, 0, time.UTC),
+			"now":                       now,
+			"-2h":                       now.Add(-2 * time.Hour),
+			"-1h30m":                    now.Add(-90 * time.Minute),
+		} {
+			t, err := ParseTime(input, now)
+			Expect(err).NotTo(HaveOccurred(), input)
+			Expect(t).To(BeTemporally("==", expected), input)
+		}
+		_, err := ParseTime("yesterday", now)
+		Expect(err).To(MatchError(ContainSubstring(`invalid time "yesterday"`)))
+		_, err = ParseTime("-2x", now)
+		Expect(err).To(MatchError(ContainSubstring("invalid relative time")))
+	})
+
+	Context("resolving to a Bracket", func() {
+		// one Event every ten minutes, starting at 13:00
+		start := time.Date(2021, 3, 1, 13, 0, 0, 0, time.UTC)
+		occurredAt := func(sequence int64) (time.Time, error) {
+			return start.Add(time.Duration(sequence-1) * 10 * time.Minute), nil
+		}
+		at := func(hour, minute int) time.Time {
+			return time.Date(2021, 3, 1, hour, minute, 0, 0, time.UTC)
+		}
+
+		It("includes both bounds", func() {
+			bracket, err := TimeRange{Since: at(14, 0), Until: at(14, 30)}.Resolve(12, occurredAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bracket).To(Equal(Range(7, 10)))
+		})
+
+		It("rounds inwards between Events", func() {
+			bracket, err := TimeRange{Since: at(14, 5), Until: at(14, 25)}.Resolve(12, occurredAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bracket).To(Equal(Range(8, 9)))
+		})
+
+		It("leaves open bounds open", func() {
+			bracket, err := TimeRange{Since: at(14, 0)}.Resolve(12, occurredAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bracket).To(Equal(Range(7, 12)))
+			bracket, err = TimeRange{Until: at(13, 15)}.Resolve(12, occurredAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bracket).To(Equal(Range(1, 2)))
+		})
+
+		It("resolves to an empty Bracket outside of the stream", func() {
+			bracket, err := TimeRange{Until: at(12, 0)}.Resolve(12, occurredAt)
+			Expect(err).NotTo(HaveOccurred())
+			bracket.Sanitize(12)
+			Expect(bracket.NextSequence).To(BeNumerically(">", bracket.LastSequence))
+		})
+
+		It("fails if an Event can't be read", func() {
+			failure := errors.New("failure")
+			_, err := TimeRange{Since: at(14, 0)}.Resolve(12, func(sequence int64) (time.Time, error) {
+				return time.Time{}, failure
+			})
+			Expect(errors.Is(err, failure)).To(BeTrue())
+		})
+	})
+
+	It("intersects Brackets", func() {
+		Expect(Range(1, -1).Intersect(Range(7, 10))).To(Equal(Range(7, 10)))
+		Expect(Range(8, 20).Intersect(Range(7, 10))).To(Equal(Range(8, 10)))
+		Expect(From(5).Intersect(Range(1, 0))).To(Equal(From(5)))
+	})
+})
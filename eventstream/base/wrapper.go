@@ -2,10 +2,11 @@ package base
 
 import "time"
 
+// EventStreamWrapper emits Events built from EventBuilders. OccurredAt is taken from its FakeClock, which IncrBy
+// advances.
 type EventStreamWrapper struct {
-	wrappedStream   EventStream
-	startTime       time.Time
-	lastEmittedTime time.Time
+	wrappedStream EventStream
+	clock         *FakeClock
 }
 
 func NewWrapper(stream EventStream) *EventStreamWrapper {
@@ -13,10 +14,14 @@ func NewWrapper(stream EventStream) *EventStreamWrapper {
 }
 
 func NewWrapperWithStartTime(stream EventStream, startTime time.Time) *EventStreamWrapper {
+	return NewWrapperWithClock(stream, NewFakeClock(startTime))
+}
+
+// NewWrapperWithClock uses the given FakeClock, e.g. to share it with the code under test.
+func NewWrapperWithClock(stream EventStream, clock *FakeClock) *EventStreamWrapper {
 	return &EventStreamWrapper{
-		wrappedStream:   stream,
-		startTime:       startTime,
-		lastEmittedTime: startTime,
+		wrappedStream: stream,
+		clock:         clock,
 	}
 }
 
@@ -35,6 +40,10 @@ func (s *EventStreamWrapper) Stream() EventStream {
 	return s.wrappedStream
 }
 
+func (s *EventStreamWrapper) Clock() *FakeClock {
+	return s.clock
+}
+
 func (s *EventStreamWrapper) Agg(a ...string) EventBuilder {
 	return func(e *Event) {
 		e.Aggregate = a
@@ -53,13 +62,13 @@ func (s *EventStreamWrapper) Type(t string) EventBuilder {
 
 func (s *EventStreamWrapper) IncrBy(duration time.Duration) EventBuilder {
 	return func(e *Event) {
-		s.lastEmittedTime = s.lastEmittedTime.Add(duration)
-		e.OccurredAt = s.lastEmittedTime
+		s.clock.Advance(duration)
+		e.OccurredAt = s.clock.Now()
 	}
 }
 
 func (s *EventStreamWrapper) After(duration time.Duration) EventBuilder {
 	return func(e *Event) {
-		e.OccurredAt = s.lastEmittedTime.Add(duration)
+		e.OccurredAt = s.clock.Now().Add(duration)
 	}
 }
@@ -0,0 +1,90 @@
+package claimcheck
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const referencePrefix = "sha256:"
+
+var (
+	ErrBlobNotFound     = errors.New("blob not found")
+	ErrInvalidReference = errors.New("invalid blob reference")
+)
+
+// BlobStore keeps offloaded Payloads and hands out references to them.
+type BlobStore interface {
+	Put(data []byte) (string, error)
+	Get(reference string) ([]byte, error)
+}
+
+// FileBlobStore is a content-addressed BlobStore keeping each blob in its own file below a directory.
+type FileBlobStore struct {
+	directory string
+}
+
+func NewFileBlobStore(directory string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return &FileBlobStore{
+		directory: directory,
+	}, nil
+}
+
+func (s *FileBlobStore) Put(data []byte) (string, error) {
+	hash := sha256.Sum256(data)
+	digest := hex.EncodeToString(hash[:])
+	filename := s.filename(digest)
+	if _, err := os.Stat(filename); err == nil {
+		return referencePrefix + digest, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), ".blob-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return "", err
+	}
+	return referencePrefix + digest, nil
+}
+
+func (s *FileBlobStore) Get(reference string) ([]byte, error) {
+	if !strings.HasPrefix(reference, referencePrefix) {
+		return nil, ErrInvalidReference
+	}
+	digest := strings.TrimPrefix(reference, referencePrefix)
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+		return nil, ErrInvalidReference
+	}
+	data, err := ioutil.ReadFile(s.filename(digest))
+	if os.IsNotExist(err) {
+		return nil, ErrBlobNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if hash := sha256.Sum256(data); hex.EncodeToString(hash[:]) != digest {
+		return nil, ErrInvalidReference
+	}
+	return data, nil
+}
+
+func (s *FileBlobStore) filename(digest string) string {
+	return filepath.Join(s.directory, digest[:2], digest)
+}
@@ -0,0 +1,99 @@
+package claimcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+const (
+	// ReferenceField is the Payload field holding the reference of an offloaded Payload.
+	ReferenceField = "$claim_check"
+	// SizeField is the Payload field holding the size of an offloaded Payload.
+	SizeField = "$claim_check_size"
+	// DefaultThreshold is the Payload size (in bytes) above which Payloads get offloaded.
+	DefaultThreshold = 256 * 1024
+)
+
+// ClaimCheck offloads large Payloads into a BlobStore, replacing them with a reference, and resolves those
+// references again on delivery. It can be registered as client.Middleware via client.Use.
+type ClaimCheck struct {
+	store     BlobStore
+	threshold int
+}
+
+type Option = func(c *ClaimCheck)
+
+func NewClaimCheck(store BlobStore, opts ...Option) *ClaimCheck {
+	c := &ClaimCheck{
+		store:     store,
+		threshold: DefaultThreshold,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Threshold sets the Payload size (in bytes, JSON encoded) above which Payloads get offloaded.
+func Threshold(bytes int) Option {
+	return func(c *ClaimCheck) {
+		c.threshold = bytes
+	}
+}
+
+func (s *ClaimCheck) Outgoing(ctx context.Context, event *base.Event) error {
+	return s.Offload(event)
+}
+
+func (s *ClaimCheck) Incoming(ctx context.Context, event *base.Event) error {
+	return s.Resolve(event)
+}
+
+// Offload replaces the Payload of the Event with a reference if it exceeds the threshold.
+func (s *ClaimCheck) Offload(event *base.Event) error {
+	if event.Payload == nil || IsReference(event) {
+		return nil
+	}
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	if len(data) <= s.threshold {
+		return nil
+	}
+	reference, err := s.store.Put(data)
+	if err != nil {
+		return fmt.Errorf("offloading payload: %w", err)
+	}
+	event.Payload = map[string]interface{}{
+		ReferenceField: reference,
+		SizeField:      len(data),
+	}
+	return nil
+}
+
+// Resolve replaces a Payload reference with the offloaded Payload.
+func (s *ClaimCheck) Resolve(event *base.Event) error {
+	if !IsReference(event) {
+		return nil
+	}
+	data, err := s.store.Get(event.Payload[ReferenceField].(string))
+	if err != nil {
+		return fmt.Errorf("resolving payload of event %d: %w", event.Sequence, err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("resolving payload of event %d: %w", event.Sequence, err)
+	}
+	event.Payload = payload
+	return nil
+}
+
+// IsReference returns whether the Payload of the Event has been offloaded.
+func IsReference(event *base.Event) bool {
+	_, ok := event.Payload[ReferenceField].(string)
+	return ok
+}
@@ -0,0 +1,13 @@
+package claimcheck
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestClaimCheck(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ClaimCheck Suite")
+}
@@ -0,0 +1,53 @@
+package claimcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("ClaimCheck", func() {
+	var store *FileBlobStore
+	var directory string
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "claimcheck")
+		Expect(err).NotTo(HaveOccurred())
+		store, err = NewFileBlobStore(directory)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	It("keeps small Payloads inline", func() {
+		c := NewClaimCheck(store, Threshold(100))
+		ev := &base.Event{Payload: map[string]interface{}{"name": "small"}}
+		Expect(c.Offload(ev)).To(Succeed())
+		Expect(IsReference(ev)).To(BeFalse())
+		Expect(ev.Payload["name"]).To(Equal("small"))
+	})
+	It("offloads large Payloads and resolves them again", func() {
+		c := NewClaimCheck(store, Threshold(100))
+		document := strings.Repeat("x", 1000)
+		ev := &base.Event{Payload: map[string]interface{}{"document": document}}
+		Expect(c.Offload(ev)).To(Succeed())
+		Expect(IsReference(ev)).To(BeTrue())
+		Expect(ev.Payload).NotTo(HaveKey("document"))
+		Expect(c.Resolve(ev)).To(Succeed())
+		Expect(ev.Payload).To(Equal(map[string]interface{}{"document": document}))
+	})
+	It("fails to resolve unknown references", func() {
+		c := NewClaimCheck(store)
+		ev := &base.Event{Payload: map[string]interface{}{ReferenceField: "sha256:" + strings.Repeat("0", 64)}}
+		Expect(c.Resolve(ev)).To(MatchError(ContainSubstring(ErrBlobNotFound.Error())))
+		ev.Payload[ReferenceField] = "../../etc/passwd"
+		Expect(c.Resolve(ev)).To(MatchError(ContainSubstring(ErrInvalidReference.Error())))
+	})
+})
@@ -0,0 +1,200 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/memory"
+)
+
+var _ = Describe("EventStore", func() {
+	var directory string
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "eventstore")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	open := func(opts ...Option) *EventStore {
+		store, err := Open(directory, opts...)
+		Expect(err).NotTo(HaveOccurred())
+		return store
+	}
+	store := func(s *EventStore, count int, aggregate ...string) {
+		for i := 0; i < count; i++ {
+			_, err := s.Store(&base.Event{Aggregate: aggregate, Type: "created", Payload: map[string]interface{}{"index": float64(i)}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+	readAll := func(s *EventStore, sel base.Selector, bracket base.Bracket) []int64 {
+		var sequences []int64
+		Expect(s.ReadAll(context.Background(), sel, bracket, func(e *base.Event) error {
+			sequences = append(sequences, e.Sequence)
+			return nil
+		})).To(Succeed())
+		return sequences
+	}
+	lastLogFile := func() string {
+		files, _ := filepath.Glob(filepath.Join(directory, "*"+logSuffix))
+		return files[len(files)-1]
+	}
+
+	Context("as EventStore of an EventStream", func() {
+		base.EventStreamSampleGroup(func() base.EventStream {
+			dir, err := ioutil.TempDir(directory, "stream")
+			Expect(err).NotTo(HaveOccurred())
+			s, err := Open(dir, Sync(SyncNever, 0))
+			Expect(err).NotTo(HaveOccurred())
+			return memory.NewEventStream(memory.Storage(s))
+		})
+	})
+
+	It("stores and reads Events", func() {
+		s := open()
+		defer s.Close()
+		ev := &base.Event{Aggregate: []string{"test", "1"}, Type: "created", Payload: map[string]interface{}{"name": "Max"}}
+		sequence, err := s.Store(ev)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sequence).To(Equal(int64(1)))
+		Expect(ev.Sequence).To(Equal(int64(1)))
+		read, err := s.Read(1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read.Aggregate).To(Equal([]string{"test", "1"}))
+		Expect(read.Payload).To(Equal(map[string]interface{}{"name": "Max"}))
+		_, err = s.Read(2)
+		Expect(err).To(Equal(base.ErrSequenceNotFound))
+	})
+
+	It("reads Brackets and Selectors across segments", func() {
+		s := open(MaxSegmentSize(200))
+		defer s.Close()
+		store(s, 10, "test", "1")
+		store(s, 10, "test", "2")
+		Expect(len(s.segments)).To(BeNumerically(">", 3))
+		Expect(readAll(s, base.Select(), base.Range(5, 14))).To(Equal([]int64{5, 6, 7, 8, 9, 10, 11, 12, 13, 14}))
+		Expect(readAll(s, base.Select(base.SelectAggregate("test", "2")), base.Range(5, 14))).To(Equal([]int64{11, 12, 13, 14}))
+		Expect(readAll(s, base.Select(), base.From(19))).To(Equal([]int64{19, 20}))
+	})
+
+	It("keeps Events across restarts", func() {
+		s := open(MaxSegmentSize(200), Sync(SyncPeriodically, 10))
+		store(s, 20, "test")
+		Expect(s.Close()).To(Succeed())
+		s = open(MaxSegmentSize(200))
+		defer s.Close()
+		Expect(s.LastKnownSequence()).To(Equal(int64(20)))
+		store(s, 1, "test")
+		Expect(readAll(s, base.Select(), base.All())).To(HaveLen(21))
+	})
+
+	It("can be closed concurrently", func() {
+		s := open(Sync(SyncPeriodically, time.Millisecond))
+		store(s, 3, "test")
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(s.Close()).To(Succeed())
+			}()
+		}
+		wg.Wait()
+	})
+
+	It("truncates a torn tail", func() {
+		s := open()
+		store(s, 3, "test")
+		Expect(s.Close()).To(Succeed())
+		info, _ := os.Stat(lastLogFile())
+		Expect(os.Truncate(lastLogFile(), info.Size()-5)).To(Succeed())
+		s = open()
+		defer s.Close()
+		Expect(s.LastKnownSequence()).To(Equal(int64(2)))
+		store(s, 1, "test")
+		ev, err := s.Read(3)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ev.Sequence).To(Equal(int64(3)))
+	})
+
+	It("truncates a corrupted tail", func() {
+		s := open()
+		store(s, 3, "test")
+		Expect(s.Close()).To(Succeed())
+		data, _ := ioutil.ReadFile(lastLogFile())
+		data[len(data)-2] ^= 0xff
+		Expect(ioutil.WriteFile(lastLogFile(), append(data, []byte(strings.Repeat("x", 100))...), 0644)).To(Succeed())
+		s = open()
+		defer s.Close()
+		Expect(s.LastKnownSequence()).To(Equal(int64(2)))
+	})
+
+	It("refuses to open a store with a gap between segments", func() {
+		s := open(MaxSegmentSize(100))
+		store(s, 10, "test")
+		Expect(s.Close()).To(Succeed())
+		files, _ := filepath.Glob(filepath.Join(directory, "*"+logSuffix))
+		Expect(os.Truncate(files[0], 10)).To(Succeed())
+		_, err := Open(directory, MaxSegmentSize(100))
+		Expect(err).To(MatchError(ContainSubstring(ErrCorruptRecord.Error())))
+	})
+})
+
+var _ = Describe("SequenceStores", func() {
+	var directory string
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "sequences")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	Context("SequenceStore", func() {
+		base.SequenceStoreSampleGroup(func() base.SequenceStore {
+			s, err := OpenSequenceStore(filepath.Join(directory, "sequences.json"))
+			Expect(err).NotTo(HaveOccurred())
+			return s
+		})
+
+		It("survives a restart", func() {
+			filename := filepath.Join(directory, "sequences.json")
+			s, _ := OpenSequenceStore(filename)
+			Expect(s.Store("test", 42)).To(Succeed())
+			s, err := OpenSequenceStore(filename)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Get("test")).To(Equal(int64(42)))
+		})
+	})
+
+	Context("DirectorySequenceStore", func() {
+		base.SequenceStoreSampleGroup(func() base.SequenceStore {
+			s, err := OpenDirectorySequenceStore(directory)
+			Expect(err).NotTo(HaveOccurred())
+			return s
+		})
+
+		It("survives a restart", func() {
+			s, _ := OpenDirectorySequenceStore(directory)
+			Expect(s.Store("test", 42)).To(Succeed())
+			s, err := OpenDirectorySequenceStore(directory)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Get("test")).To(Equal(int64(42)))
+		})
+	})
+})
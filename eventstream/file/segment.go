@@ -0,0 +1,262 @@
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// headerSize is the size of a record header: payload length, CRC and sequence.
+	headerSize     = 4 + 4 + 8
+	indexEntrySize = 8
+	logSuffix      = ".log"
+	indexSuffix    = ".idx"
+)
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	ErrCorruptRecord = errors.New("corrupt record")
+)
+
+// segment is a single log file holding consecutive records starting at firstSequence, together with the index of
+// their offsets.
+type segment struct {
+	firstSequence int64
+	log           *os.File
+	index         *os.File
+	offsets       []int64
+	size          int64
+}
+
+func segmentName(directory string, firstSequence int64, suffix string) string {
+	return filepath.Join(directory, fmt.Sprintf("%020d%s", firstSequence, suffix))
+}
+
+func createSegment(directory string, firstSequence int64) (*segment, error) {
+	log, err := os.OpenFile(segmentName(directory, firstSequence, logSuffix), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(segmentName(directory, firstSequence, indexSuffix), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	return &segment{
+		firstSequence: firstSequence,
+		log:           log,
+		index:         index,
+	}, nil
+}
+
+// openSegment opens an existing segment. When verify is set, all records are checked and a torn tail gets truncated,
+// otherwise the index is trusted as long as it is consistent with the size of the log.
+func openSegment(directory string, firstSequence int64, verify bool) (*segment, error) {
+	log, err := os.OpenFile(segmentName(directory, firstSequence, logSuffix), os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(segmentName(directory, firstSequence, indexSuffix), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		log.Close()
+		return nil, err
+	}
+	seg := &segment{
+		firstSequence: firstSequence,
+		log:           log,
+		index:         index,
+	}
+	if !verify && seg.loadIndex() == nil {
+		return seg, nil
+	}
+	if err := seg.recover(); err != nil {
+		seg.close()
+		return nil, err
+	}
+	return seg, nil
+}
+
+func (s *segment) lastSequence() int64 {
+	return s.firstSequence + int64(len(s.offsets)) - 1
+}
+
+func (s *segment) contains(sequence int64) bool {
+	return sequence >= s.firstSequence && sequence <= s.lastSequence()
+}
+
+// loadIndex reads the index file and checks that it covers the log file exactly.
+func (s *segment) loadIndex() error {
+	info, err := s.log.Stat()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(s.index)
+	if err != nil {
+		return err
+	}
+	if len(data)%indexEntrySize != 0 {
+		return ErrCorruptRecord
+	}
+	offsets := make([]int64, len(data)/indexEntrySize)
+	for i := range offsets {
+		offsets[i] = int64(binary.BigEndian.Uint64(data[i*indexEntrySize:]))
+	}
+	size := int64(0)
+	if len(offsets) > 0 {
+		var header [headerSize]byte
+		last := offsets[len(offsets)-1]
+		if _, err := s.log.ReadAt(header[:], last); err != nil {
+			return ErrCorruptRecord
+		}
+		size = last + headerSize + int64(binary.BigEndian.Uint32(header[0:4]))
+	}
+	if size != info.Size() {
+		return ErrCorruptRecord
+	}
+	s.offsets = offsets
+	s.size = size
+	return nil
+}
+
+// recover scans all records of the log, truncates it after the last intact record and rewrites the index.
+func (s *segment) recover() error {
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	info, err := s.log.Stat()
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(s.log)
+	var offsets []int64
+	offset := int64(0)
+	for {
+		_, length, err := readRecord(reader, s.firstSequence+int64(len(offsets)), info.Size()-offset)
+		if err != nil {
+			break
+		}
+		offsets = append(offsets, offset)
+		offset += headerSize + int64(length)
+	}
+	if err := s.log.Truncate(offset); err != nil {
+		return err
+	}
+	if err := s.log.Sync(); err != nil {
+		return err
+	}
+	index := make([]byte, len(offsets)*indexEntrySize)
+	for i, o := range offsets {
+		binary.BigEndian.PutUint64(index[i*indexEntrySize:], uint64(o))
+	}
+	if err := s.index.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.index.WriteAt(index, 0); err != nil {
+		return err
+	}
+	if err := s.index.Sync(); err != nil {
+		return err
+	}
+	s.offsets = offsets
+	s.size = offset
+	return nil
+}
+
+func (s *segment) append(sequence int64, payload []byte) error {
+	record := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint64(record[8:16], uint64(sequence))
+	copy(record[headerSize:], payload)
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(record[8:], crcTable))
+	if _, err := s.log.WriteAt(record, s.size); err != nil {
+		return err
+	}
+	var entry [indexEntrySize]byte
+	binary.BigEndian.PutUint64(entry[:], uint64(s.size))
+	if _, err := s.index.WriteAt(entry[:], int64(len(s.offsets))*indexEntrySize); err != nil {
+		return err
+	}
+	s.offsets = append(s.offsets, s.size)
+	s.size += int64(len(record))
+	return nil
+}
+
+func (s *segment) read(sequence int64) ([]byte, error) {
+	offset := s.offsets[sequence-s.firstSequence]
+	reader := io.NewSectionReader(s.log, offset, s.size-offset)
+	payload, _, err := readRecord(reader, sequence, s.size-offset)
+	return payload, err
+}
+
+// segmentView is a snapshot of a segment which can be read without holding the EventStore's lock.
+type segmentView struct {
+	firstSequence int64
+	log           *os.File
+	offsets       []int64
+	size          int64
+}
+
+func (s *segment) view() segmentView {
+	return segmentView{
+		firstSequence: s.firstSequence,
+		log:           s.log,
+		offsets:       s.offsets,
+		size:          s.size,
+	}
+}
+
+func (v segmentView) lastSequence() int64 {
+	return v.firstSequence + int64(len(v.offsets)) - 1
+}
+
+// reader returns a buffered reader positioned at the record of the given sequence.
+func (v segmentView) reader(sequence int64) io.Reader {
+	offset := v.offsets[sequence-v.firstSequence]
+	return bufio.NewReader(io.NewSectionReader(v.log, offset, v.size-offset))
+}
+
+func (s *segment) sync() error {
+	if err := s.log.Sync(); err != nil {
+		return err
+	}
+	return s.index.Sync()
+}
+
+func (s *segment) close() error {
+	err := s.log.Close()
+	if indexErr := s.index.Close(); err == nil {
+		err = indexErr
+	}
+	return err
+}
+
+// readRecord reads and validates a single record, which is expected to carry the given sequence and to fit into the
+// remaining bytes.
+func readRecord(reader io.Reader, sequence int64, remaining int64) ([]byte, uint32, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, 0, ErrCorruptRecord
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	if int64(binary.BigEndian.Uint64(header[8:16])) != sequence || headerSize+int64(length) > remaining {
+		return nil, 0, ErrCorruptRecord
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, 0, ErrCorruptRecord
+	}
+	crc := crc32.Update(crc32.Checksum(header[8:16], crcTable), crcTable, payload)
+	if crc != checksum {
+		return nil, 0, ErrCorruptRecord
+	}
+	return payload, length, nil
+}
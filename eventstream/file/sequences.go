@@ -0,0 +1,128 @@
+package file
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const sequenceSuffix = ".seq"
+
+// SequenceStore keeps the last acknowledged sequences of all persistent clients in a single JSON file, which is
+// replaced atomically on every change.
+type SequenceStore struct {
+	mutex     sync.Mutex
+	filename  string
+	sequences map[string]int64
+}
+
+func OpenSequenceStore(filename string) (*SequenceStore, error) {
+	s := &SequenceStore{
+		filename:  filename,
+		sequences: make(map[string]int64),
+	}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.sequences); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SequenceStore) Get(persistentClientID string) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sequences[persistentClientID], nil
+}
+
+func (s *SequenceStore) Store(persistentClientID string, sequence int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	previous, existed := s.sequences[persistentClientID]
+	s.sequences[persistentClientID] = sequence
+	data, err := json.Marshal(s.sequences)
+	if err == nil {
+		err = writeFileAtomically(s.filename, data)
+	}
+	if err != nil {
+		if existed {
+			s.sequences[persistentClientID] = previous
+		} else {
+			delete(s.sequences, persistentClientID)
+		}
+	}
+	return err
+}
+
+// DirectorySequenceStore keeps the last acknowledged sequence of each persistent client in its own file.
+type DirectorySequenceStore struct {
+	mutex     sync.Mutex
+	directory string
+}
+
+func OpenDirectorySequenceStore(directory string) (*DirectorySequenceStore, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return &DirectorySequenceStore{
+		directory: directory,
+	}, nil
+}
+
+func (s *DirectorySequenceStore) Get(persistentClientID string) (int64, error) {
+	data, err := ioutil.ReadFile(s.filename(persistentClientID))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func (s *DirectorySequenceStore) Store(persistentClientID string, sequence int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return writeFileAtomically(s.filename(persistentClientID), []byte(strconv.FormatInt(sequence, 10)+"\n"))
+}
+
+func (s *DirectorySequenceStore) filename(persistentClientID string) string {
+	return filepath.Join(s.directory, url.PathEscape(persistentClientID)+sequenceSuffix)
+}
+
+// writeFileAtomically replaces the file by writing to a temporary file first and renaming it afterwards.
+func writeFileAtomically(filename string, data []byte) error {
+	directory := filepath.Dir(filename)
+	tmp, err := ioutil.TempFile(directory, "."+filepath.Base(filename)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+	if dir, err := os.Open(directory); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+const DefaultMaxSegmentSize = 64 * 1024 * 1024
+
+var ErrClosed = errors.New("event store is closed")
+
+// SyncPolicy determines when appended records are flushed to stable storage.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes after every stored Event.
+	SyncAlways SyncPolicy = iota
+	// SyncPeriodically flushes in the background in a fixed interval.
+	SyncPeriodically
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// EventStore is a durable base.EventStore keeping Events in an append-only log, split into segments of limited size.
+// Every record carries a CRC, and a torn tail left by a crash is truncated when the EventStore is opened.
+type EventStore struct {
+	mutex          sync.RWMutex
+	directory      string
+	maxSegmentSize int64
+	syncPolicy     SyncPolicy
+	syncInterval   time.Duration
+	segments       []*segment
+	dirty          bool
+	closed         bool
+	stop           chan struct{}
+	stopOnce       sync.Once
+	stopped        chan struct{}
+}
+
+type Option = func(s *EventStore)
+
+// MaxSegmentSize sets the size (in bytes) after which a new segment is started.
+func MaxSegmentSize(size int64) Option {
+	return func(s *EventStore) {
+		s.maxSegmentSize = size
+	}
+}
+
+// Sync sets the SyncPolicy and, for SyncPeriodically, the interval between flushes.
+func Sync(policy SyncPolicy, interval time.Duration) Option {
+	return func(s *EventStore) {
+		s.syncPolicy = policy
+		s.syncInterval = interval
+	}
+}
+
+// Open opens (or creates) the EventStore in the given directory, recovering from an unclean shutdown if necessary.
+func Open(directory string, opts ...Option) (*EventStore, error) {
+	s := &EventStore{
+		directory:      directory,
+		maxSegmentSize: DefaultMaxSegmentSize,
+		syncPolicy:     SyncAlways,
+		syncInterval:   time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	if err := s.openSegments(); err != nil {
+		s.closeSegments()
+		return nil, err
+	}
+	if s.syncPolicy == SyncPeriodically {
+		s.stop = make(chan struct{})
+		s.stopped = make(chan struct{})
+		go s.syncPeriodically()
+	}
+	return s, nil
+}
+
+func (s *EventStore) openSegments() error {
+	files, err := ioutil.ReadDir(s.directory)
+	if err != nil {
+		return err
+	}
+	var firstSequences []int64
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), logSuffix) {
+			continue
+		}
+		if sequence, err := strconv.ParseInt(strings.TrimSuffix(file.Name(), logSuffix), 10, 64); err == nil {
+			firstSequences = append(firstSequences, sequence)
+		}
+	}
+	sort.Slice(firstSequences, func(i, j int) bool { return firstSequences[i] < firstSequences[j] })
+	for i, firstSequence := range firstSequences {
+		last := i == len(firstSequences)-1
+		seg, err := openSegment(s.directory, firstSequence, last)
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, seg)
+		if !last && seg.lastSequence()+1 != firstSequences[i+1] {
+			return fmt.Errorf("segment %d ends at sequence %d: %w", firstSequence, seg.lastSequence(), ErrCorruptRecord)
+		}
+	}
+	if len(s.segments) == 0 {
+		seg, err := createSegment(s.directory, 1)
+		if err != nil {
+			return err
+		}
+		s.segments = append(s.segments, seg)
+	}
+	return nil
+}
+
+func (s *EventStore) Store(event *base.Event) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	active := s.segments[len(s.segments)-1]
+	sequence := active.lastSequence() + 1
+	if active.size >= s.maxSegmentSize {
+		if err := active.sync(); err != nil {
+			return 0, err
+		}
+		seg, err := createSegment(s.directory, sequence)
+		if err != nil {
+			return 0, err
+		}
+		s.segments = append(s.segments, seg)
+		active = seg
+	}
+	stored := *event
+	stored.Sequence = sequence
+	payload, err := json.Marshal(&stored)
+	if err != nil {
+		return 0, err
+	}
+	if err := active.append(sequence, payload); err != nil {
+		return 0, err
+	}
+	if s.syncPolicy == SyncAlways {
+		if err := active.sync(); err != nil {
+			return 0, err
+		}
+	} else {
+		s.dirty = true
+	}
+	event.Sequence = sequence
+	return sequence, nil
+}
+
+func (s *EventStore) LastKnownSequence() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.segments[len(s.segments)-1].lastSequence()
+}
+
+func (s *EventStore) Read(sequence int64) (*base.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	return s.read(sequence)
+}
+
+func (s *EventStore) read(sequence int64) (*base.Event, error) {
+	index := sort.Search(len(s.segments), func(i int) bool { return s.segments[i].lastSequence() >= sequence })
+	if index == len(s.segments) || !s.segments[index].contains(sequence) {
+		return nil, base.ErrSequenceNotFound
+	}
+	payload, err := s.segments[index].read(sequence)
+	if err != nil {
+		return nil, fmt.Errorf("reading sequence %d: %w", sequence, err)
+	}
+	var event base.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("decoding sequence %d: %w", sequence, err)
+	}
+	return &event, nil
+}
+
+// ReadAll seeks directly to the first sequence of the Bracket using the index and reads sequentially from there.
+func (s *EventStore) ReadAll(ctx context.Context, sel base.Selector, bracket base.Bracket, handler base.EventHandler) error {
+	s.mutex.RLock()
+	if s.closed {
+		s.mutex.RUnlock()
+		return ErrClosed
+	}
+	views := make([]segmentView, len(s.segments))
+	for i, seg := range s.segments {
+		views[i] = seg.view()
+	}
+	s.mutex.RUnlock()
+	bracket.Sanitize(views[len(views)-1].lastSequence())
+	for _, view := range views {
+		first, last := view.firstSequence, view.lastSequence()
+		if last < bracket.NextSequence || first > bracket.LastSequence {
+			continue
+		}
+		if first < bracket.NextSequence {
+			first = bracket.NextSequence
+		}
+		if last > bracket.LastSequence {
+			last = bracket.LastSequence
+		}
+		reader := view.reader(first)
+		for sequence := first; sequence <= last; sequence++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			payload, _, err := readRecord(reader, sequence, view.size)
+			if err != nil {
+				return fmt.Errorf("reading sequence %d: %w", sequence, err)
+			}
+			var event base.Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return fmt.Errorf("decoding sequence %d: %w", sequence, err)
+			}
+			if sel.Matches(&event) {
+				if err := handler(&event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes all outstanding records to stable storage.
+func (s *EventStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.flush()
+}
+
+func (s *EventStore) flush() error {
+	if !s.dirty || s.closed {
+		return nil
+	}
+	if err := s.segments[len(s.segments)-1].sync(); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+func (s *EventStore) syncPeriodically() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.Flush()
+		}
+	}
+}
+
+// Close flushes all outstanding records and closes all segments.
+func (s *EventStore) Close() error {
+	if s.stop != nil {
+		s.stopOnce.Do(func() { close(s.stop) })
+		<-s.stopped
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.dirty = true
+	err := s.flush()
+	if closeErr := s.closeSegments(); err == nil {
+		err = closeErr
+	}
+	s.closed = true
+	return err
+}
+
+func (s *EventStore) closeSegments() error {
+	var err error
+	for _, seg := range s.segments {
+		if closeErr := seg.close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+type or struct{ left, right node }
+
+func (n *or) eval(e *base.Event) interface{} {
+	return n.left.eval(e) == true || n.right.eval(e) == true
+}
+
+type and struct{ left, right node }
+
+func (n *and) eval(e *base.Event) interface{} {
+	return n.left.eval(e) == true && n.right.eval(e) == true
+}
+
+type not struct{ operand node }
+
+func (n *not) eval(e *base.Event) interface{} {
+	return n.operand.eval(e) != true
+}
+
+type truthy struct{ operand node }
+
+func (n *truthy) eval(e *base.Event) interface{} {
+	return n.operand.eval(e) == true
+}
+
+type literal struct{ value interface{} }
+
+func (n *literal) eval(e *base.Event) interface{} {
+	return n.value
+}
+
+type path struct {
+	field    string
+	segments []string
+}
+
+func (n *path) eval(e *base.Event) interface{} {
+	switch n.field {
+	case FieldType:
+		return e.Type
+	case FieldSequence:
+		return float64(e.Sequence)
+	case FieldOccurredAt:
+		return e.OccurredAt
+	case FieldAggregate:
+		if len(n.segments) == 0 {
+			return strings.Join(e.Aggregate, ".")
+		}
+		index, _ := strconv.Atoi(n.segments[0])
+		if index < 0 || index >= len(e.Aggregate) {
+			return nil
+		}
+		return e.Aggregate[index]
+	}
+	var value interface{} = e.Payload
+	for _, segment := range n.segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil
+			}
+			value = v[index]
+		default:
+			return nil
+		}
+	}
+	return normalize(value)
+}
+
+type match struct {
+	operand node
+	re      *regexp.Regexp
+}
+
+func (n *match) eval(e *base.Event) interface{} {
+	s, ok := n.operand.eval(e).(string)
+	return ok && n.re.MatchString(s)
+}
+
+type comparison struct {
+	op          string
+	left, right node
+}
+
+func (n *comparison) eval(e *base.Event) interface{} {
+	left, right := n.left.eval(e), n.right.eval(e)
+	switch n.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	}
+	c, ok := compare(left, right)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func equal(left, right interface{}) bool {
+	if c, ok := compare(left, right); ok {
+		return c == 0
+	}
+	switch l := left.(type) {
+	case nil, bool:
+		return left == right
+	case string:
+		r, ok := right.(string)
+		return ok && l == r
+	}
+	return false
+}
+
+// compare orders two numbers, strings or times and reports whether they were comparable.
+func compare(left, right interface{}) (int, bool) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			return sign(l - r), true
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), true
+		}
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return sign(float64(l.Sub(r))), true
+		}
+	}
+	return 0, false
+}
+
+func sign(f float64) int {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+// normalize converts all numbers to float64, as Payloads may hold them in any numeric type.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
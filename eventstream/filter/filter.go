@@ -0,0 +1,342 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// Fields which can be referenced in expressions besides payload.<path>.
+const (
+	FieldPayload    = "payload"
+	FieldType       = "type"
+	FieldAggregate  = "aggregate"
+	FieldSequence   = "sequence"
+	FieldOccurredAt = "occurred_at"
+)
+
+// SyntaxError describes why an expression could not be compiled and where.
+type SyntaxError struct {
+	Expression string
+	Position   int
+	Message    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d\n    %s\n    %s^", e.Message, e.Position+1, e.Expression, strings.Repeat(" ", e.Position))
+}
+
+// Filter is a compiled expression over the fields of an Event, like
+//
+//	payload.amount > 100 && payload.currency == "EUR"
+//
+// Paths (payload.<key>..., type, aggregate, sequence, occurred_at) are compared with ==, !=, <, <=, > and >= to
+// strings, numbers, true, false and null, or matched against a regular expression with =~. Comparisons are combined
+// with &&, || and ! and grouped by parentheses; a path on its own is true if its value is true. Missing fields are
+// null, and comparisons of mismatching types are false.
+type Filter struct {
+	expression string
+	root       node
+}
+
+// Compile parses and validates the expression.
+func Compile(expression string) (*Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expression: expression, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", t))
+	}
+	return &Filter{expression: expression, root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the expression is invalid.
+func MustCompile(expression string) *Filter {
+	f, err := Compile(expression)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (f *Filter) String() string {
+	return f.expression
+}
+
+func (f *Filter) Matches(event *base.Event) bool {
+	return f.root.eval(event) == true
+}
+
+// Handler wraps the EventHandler so it only receives the Events matching the Filter.
+func (f *Filter) Handler(handler base.EventHandler) base.EventHandler {
+	return func(e *base.Event) error {
+		if !f.Matches(e) {
+			return nil
+		}
+		return handler(e)
+	}
+}
+
+// Field is a compiled path to a single field of an Event, as referenced in expressions.
+type Field struct {
+	name string
+	path node
+}
+
+// CompileField parses a path like payload.id, aggregate.1 or type.
+func CompileField(name string) (*Field, error) {
+	tokens, err := tokenize(name)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{expression: name, tokens: tokens}
+	t := p.next()
+	if t.kind != tokenIdentifier {
+		return nil, p.errorAt(t, fmt.Sprintf("expected a field but found %s", t))
+	}
+	path, err := p.parsePath(t)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorAt(t, fmt.Sprintf("unexpected %s", t))
+	}
+	return &Field{name: name, path: path}, nil
+}
+
+// MustCompileField is like CompileField but panics if the path is invalid.
+func MustCompileField(name string) *Field {
+	f, err := CompileField(name)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (f *Field) String() string {
+	return f.name
+}
+
+// Value returns the value of the field in the Event, or nil if it is missing. Numbers are float64.
+func (f *Field) Value(event *base.Event) interface{} {
+	return f.path.eval(event)
+}
+
+type node interface {
+	eval(event *base.Event) interface{}
+}
+
+type parser struct {
+	expression string
+	tokens     []token
+	current    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.current]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.current]
+	if t.kind != tokenEOF {
+		p.current++
+	}
+	return t
+}
+
+func (p *parser) errorAt(t token, message string) error {
+	return &SyntaxError{Expression: p.expression, Position: t.position, Message: message}
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "||" && p.peek().kind == tokenOperator {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "&&" && p.peek().kind == tokenOperator {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &and{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenOperator && t.text == "!":
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{operand}, nil
+	case t.kind == tokenLeftParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRightParen {
+			return nil, p.errorAt(closing, fmt.Sprintf("expected \")\" but found %s", closing))
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	leftToken := p.peek()
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	if op.kind != tokenOperator || op.text == "&&" || op.text == "||" || op.text == "!" {
+		if _, ok := left.(*path); !ok {
+			return nil, p.errorAt(leftToken, fmt.Sprintf("expected a comparison, but %s is a constant", leftToken))
+		}
+		return &truthy{left}, nil
+	}
+	p.next()
+	rightToken := p.peek()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch op.text {
+	case "=~":
+		lit, _ := right.(*literal)
+		if lit == nil {
+			lit = &literal{}
+		}
+		pattern, ok := lit.value.(string)
+		if !ok {
+			return nil, p.errorAt(rightToken, "=~ expects a regular expression in a string")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, p.errorAt(rightToken, fmt.Sprintf("invalid regular expression: %s", err))
+		}
+		return &match{left, re}, nil
+	case "<", "<=", ">", ">=":
+		for _, side := range []struct {
+			node  node
+			token token
+		}{{left, leftToken}, {right, rightToken}} {
+			if lit, ok := side.node.(*literal); ok {
+				switch lit.value.(type) {
+				case float64, string, time.Time:
+				default:
+					return nil, p.errorAt(side.token, fmt.Sprintf("%s needs numbers or strings, but %s is neither", op.text, side.token))
+				}
+			}
+		}
+	}
+	if err := p.convertTimes(left, right, rightToken); err != nil {
+		return nil, err
+	}
+	if err := p.convertTimes(right, left, leftToken); err != nil {
+		return nil, err
+	}
+	return &comparison{op.text, left, right}, nil
+}
+
+// convertTimes parses string literals compared to occurred_at as RFC3339 times.
+func (p *parser) convertTimes(field, other node, otherToken token) error {
+	if f, ok := field.(*path); !ok || f.field != FieldOccurredAt {
+		return nil
+	}
+	lit, ok := other.(*literal)
+	if !ok {
+		return nil
+	}
+	if s, ok := lit.value.(string); ok {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return p.errorAt(otherToken, fmt.Sprintf("%s expects an RFC3339 time", FieldOccurredAt))
+		}
+		lit.value = t
+	}
+	return nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return &literal{t.value}, nil
+	case tokenIdentifier:
+		switch t.text {
+		case "true":
+			return &literal{true}, nil
+		case "false":
+			return &literal{false}, nil
+		case "null":
+			return &literal{nil}, nil
+		}
+		return p.parsePath(t)
+	}
+	return nil, p.errorAt(t, fmt.Sprintf("expected a field or a value but found %s", t))
+}
+
+func (p *parser) parsePath(t token) (node, error) {
+	segments := strings.Split(t.text, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, p.errorAt(t, fmt.Sprintf("empty segment in path %s", t))
+		}
+	}
+	switch segments[0] {
+	case FieldPayload:
+		if len(segments) == 1 {
+			return nil, p.errorAt(t, "expected a path into the payload, like payload.amount")
+		}
+	case FieldAggregate:
+		if len(segments) > 2 {
+			return nil, p.errorAt(t, "expected aggregate or aggregate.<index>")
+		}
+		if len(segments) == 2 {
+			if _, err := strconv.Atoi(segments[1]); err != nil {
+				return nil, p.errorAt(t, "expected aggregate or aggregate.<index>")
+			}
+		}
+	case FieldType, FieldSequence, FieldOccurredAt:
+		if len(segments) > 1 {
+			return nil, p.errorAt(t, fmt.Sprintf("%s has no fields", segments[0]))
+		}
+	default:
+		return nil, p.errorAt(t, fmt.Sprintf("unknown field %q, expected payload.<path>, %s, %s, %s or %s",
+			segments[0], FieldType, FieldAggregate, FieldSequence, FieldOccurredAt))
+	}
+	return &path{field: segments[0], segments: segments[1:]}, nil
+}
@@ -0,0 +1,109 @@
+package filter
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Filter", func() {
+	event := &base.Event{
+		Sequence:   42,
+		Aggregate:  []string{"orders", "4711"},
+		Type:       "placed",
+		OccurredAt: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		Payload: map[string]interface{}{
+			"amount":   150.0,
+			"quantity": 3,
+			"currency": "EUR",
+			"express":  true,
+			"customer": map[string]interface{}{"name": "Jane Doe", "tier": "gold"},
+			"items":    []interface{}{map[string]interface{}{"sku": "A-1"}},
+		},
+	}
+
+	DescribeTable("evaluates expressions",
+		func(expression string, matches bool) {
+			f, err := Compile(expression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Matches(event)).To(Equal(matches))
+		},
+		Entry("number comparison", "payload.amount > 100", true),
+		Entry("integer payload", "payload.quantity == 3", true),
+		Entry("conjunction", `payload.amount > 100 && payload.currency == "EUR"`, true),
+		Entry("failing conjunction", `payload.amount > 100 && payload.currency == 'USD'`, false),
+		Entry("disjunction", `payload.amount > 1000 || payload.express`, true),
+		Entry("negation and grouping", `!(payload.amount < 100 || payload.currency != "EUR")`, true),
+		Entry("nested field", `payload.customer.tier == "gold"`, true),
+		Entry("array index", `payload.items.0.sku == "A-1"`, true),
+		Entry("missing field is null", `payload.discount == null`, true),
+		Entry("missing field doesn't compare", `payload.discount < 10`, false),
+		Entry("mismatching types", `payload.currency > 10`, false),
+		Entry("regular expression", `payload.customer.name =~ "^Jane"`, true),
+		Entry("type", `type == "placed"`, true),
+		Entry("aggregate", `aggregate == "orders.4711"`, true),
+		Entry("aggregate segment", `aggregate.0 == "orders" && aggregate.5 == null`, true),
+		Entry("sequence", `sequence >= 42`, true),
+		Entry("occurred_at", `occurred_at < "2021-03-02T00:00:00Z"`, true),
+		Entry("negative number", `payload.amount > -1.5e2`, true),
+	)
+
+	DescribeTable("reports invalid expressions",
+		func(expression string, message string, column int) {
+			_, err := Compile(expression)
+			var syntaxErr *SyntaxError
+			Expect(errors.As(err, &syntaxErr)).To(BeTrue())
+			Expect(syntaxErr.Message).To(ContainSubstring(message))
+			Expect(syntaxErr.Position + 1).To(Equal(column))
+		},
+		Entry("unknown field", "amount > 100", `unknown field "amount"`, 1),
+		Entry("payload without path", "payload == null", "path into the payload", 1),
+		Entry("missing operand", "payload.amount >", "expected a field or a value but found end of expression", 17),
+		Entry("unbalanced parentheses", "(payload.amount > 100", `expected ")"`, 22),
+		Entry("trailing tokens", "payload.amount > 100 100", `unexpected "100"`, 22),
+		Entry("constant condition", "true && payload.express", "is a constant", 1),
+		Entry("ordering of booleans", "payload.express < true", "needs numbers or strings", 19),
+		Entry("invalid regular expression", `payload.name =~ "("`, "invalid regular expression", 17),
+		Entry("regular expression not a string", `payload.name =~ 1`, "expects a regular expression", 17),
+		Entry("invalid time", `occurred_at > "yesterday"`, "RFC3339", 15),
+		Entry("unterminated string", `payload.name == "Jane`, "unterminated string", 17),
+		Entry("unexpected character", `payload.name = "Jane"`, `unexpected character '='`, 14),
+	)
+
+	It("points at the error", func() {
+		_, err := Compile("payload.amount >")
+		Expect(err.Error()).To(Equal("expected a field or a value but found end of expression at column 17\n" +
+			"    payload.amount >\n" +
+			"                    ^"))
+	})
+
+	It("wraps EventHandlers", func() {
+		var handled []int64
+		handler := MustCompile("sequence > 1").Handler(func(e *base.Event) error {
+			handled = append(handled, e.Sequence)
+			return nil
+		})
+		for i := int64(1); i <= 3; i++ {
+			Expect(handler(&base.Event{Sequence: i})).To(Succeed())
+		}
+		Expect(handled).To(Equal([]int64{2, 3}))
+	})
+
+	It("compiles single fields", func() {
+		field, err := CompileField("payload.customer.name")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(field.Value(event)).To(Equal("Jane Doe"))
+		Expect(MustCompileField("aggregate.1").Value(event)).To(Equal("4711"))
+		Expect(MustCompileField("payload.quantity").Value(event)).To(Equal(3.0))
+		Expect(MustCompileField("payload.missing").Value(event)).To(BeNil())
+		_, err = CompileField("payload.amount > 1")
+		Expect(err).To(HaveOccurred())
+		_, err = CompileField("customer")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,162 @@
+package matchers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// HaveType succeeds if the actual Event has the given Type.
+func HaveType(eventType string) types.GomegaMatcher {
+	return &eventMatcher{
+		description: fmt.Sprintf("to have type %q", eventType),
+		match: func(e *base.Event) (bool, error) {
+			return e.Type == eventType, nil
+		},
+	}
+}
+
+// HaveAggregate succeeds if the actual Event belongs to exactly the given Aggregate.
+func HaveAggregate(aggregate ...string) types.GomegaMatcher {
+	return &eventMatcher{
+		description: fmt.Sprintf("to have aggregate %q", strings.Join(aggregate, ".")),
+		match: func(e *base.Event) (bool, error) {
+			if len(e.Aggregate) != len(aggregate) {
+				return false, nil
+			}
+			for i, token := range aggregate {
+				if e.Aggregate[i] != token {
+					return false, nil
+				}
+			}
+			return true, nil
+		},
+	}
+}
+
+// MatchSelector succeeds if the given Selector (a base.Selector, *base.Selector or a string to be parsed) matches the
+// actual Event.
+func MatchSelector(selector interface{}) types.GomegaMatcher {
+	var sel *base.Selector
+	var err error
+	switch s := selector.(type) {
+	case base.Selector:
+		sel = &s
+	case *base.Selector:
+		sel = s
+	case string:
+		sel, err = base.ParseSelector(s)
+	default:
+		err = fmt.Errorf("MatchSelector expects a Selector or a string, got %T", selector)
+	}
+	return &eventMatcher{
+		description: fmt.Sprintf("to match selector %s", format.Object(selector, 0)),
+		match: func(e *base.Event) (bool, error) {
+			if err != nil {
+				return false, err
+			}
+			return sel.Matches(e), nil
+		},
+	}
+}
+
+// HavePayloadField succeeds if the Payload of the actual Event contains the dot-separated path and its value matches
+// the given value, which may also be a matcher. Numbers are compared by value regardless of their type.
+func HavePayloadField(path string, value interface{}) types.GomegaMatcher {
+	matcher, ok := value.(types.GomegaMatcher)
+	if !ok {
+		if isNumber(value) {
+			matcher = gomega.BeNumerically("==", value)
+		} else {
+			matcher = gomega.Equal(value)
+		}
+	}
+	return &eventMatcher{
+		description: fmt.Sprintf("to have payload field %q matching %s", path, format.Object(value, 0)),
+		match: func(e *base.Event) (bool, error) {
+			field, ok := lookup(e.Payload, path)
+			if !ok {
+				return false, nil
+			}
+			if isNumber(value) && !isNumber(field) {
+				return false, nil
+			}
+			return matcher.Match(field)
+		},
+	}
+}
+
+type eventMatcher struct {
+	description string
+	match       func(e *base.Event) (bool, error)
+}
+
+func (m *eventMatcher) Match(actual interface{}) (bool, error) {
+	event, err := toEvent(actual)
+	if err != nil {
+		return false, err
+	}
+	return m.match(event)
+}
+
+func (m *eventMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n    %s\n%s", describe(actual), m.description)
+}
+
+func (m *eventMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n    %s\nnot %s", describe(actual), m.description)
+}
+
+func toEvent(actual interface{}) (*base.Event, error) {
+	switch e := actual.(type) {
+	case *base.Event:
+		if e == nil {
+			return nil, fmt.Errorf("expected an Event, got nil")
+		}
+		return e, nil
+	case base.Event:
+		return &e, nil
+	default:
+		return nil, fmt.Errorf("expected an Event, got %T", actual)
+	}
+}
+
+// describe renders Events the way the text formatter of the CLI does.
+func describe(actual interface{}) string {
+	e, err := toEvent(actual)
+	if err != nil {
+		return format.Object(actual, 1)
+	}
+	s := fmt.Sprintf("%d » %s/%s", e.Sequence, strings.Join(e.Aggregate, "."), e.Type)
+	if len(e.Payload) > 0 {
+		s += fmt.Sprintf(" » %v", e.Payload)
+	}
+	return s
+}
+
+func lookup(payload map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = payload
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package matchers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// ContainEventsInOrder succeeds if the actual Events contain Events matching the given elements in the given order,
+// not necessarily adjacent. Elements may be matchers or Events, the latter matching on Aggregate and Type.
+// The actual value may be a slice of Events or a base.EventStream, which is streamed completely on every attempt,
+// so it can be used with Eventually:
+//
+//	Eventually(stream).Should(ContainEventsInOrder(HaveType("created"), HaveType("deleted")))
+func ContainEventsInOrder(elements ...interface{}) types.GomegaMatcher {
+	matchers := make([]types.GomegaMatcher, len(elements))
+	for i, element := range elements {
+		matchers[i] = toMatcher(element)
+	}
+	return &eventsInOrderMatcher{
+		elements: elements,
+		matchers: matchers,
+	}
+}
+
+// EventsOf returns a function collecting all Events of the EventStream matching the Selector, for use with
+// Eventually.
+func EventsOf(stream base.EventStream, sel base.Selector) func() ([]*base.Event, error) {
+	return func() ([]*base.Event, error) {
+		var events []*base.Event
+		err := stream.Stream(context.Background(), sel, base.All(), func(e *base.Event) error {
+			events = append(events, e)
+			return nil
+		})
+		return events, err
+	}
+}
+
+type eventsInOrderMatcher struct {
+	elements []interface{}
+	matchers []types.GomegaMatcher
+	events   []*base.Event
+	missing  int
+}
+
+func (m *eventsInOrderMatcher) Match(actual interface{}) (bool, error) {
+	events, err := toEvents(actual)
+	if err != nil {
+		return false, err
+	}
+	m.events = events
+	next := 0
+	for _, event := range events {
+		if next == len(m.matchers) {
+			break
+		}
+		ok, err := m.matchers[next].Match(event)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			next++
+		}
+	}
+	m.missing = next
+	return next == len(m.matchers), nil
+}
+
+func (m *eventsInOrderMatcher) FailureMessage(actual interface{}) string {
+	var b strings.Builder
+	b.WriteString("Expected\n")
+	m.writeEvents(&b)
+	fmt.Fprintf(&b, "to contain %d events in order, but found no match for element %d after matching %d:\n    %s",
+		len(m.matchers), m.missing+1, m.missing, describeElement(m.elements[m.missing], m.matchers[m.missing]))
+	return b.String()
+}
+
+func (m *eventsInOrderMatcher) NegatedFailureMessage(actual interface{}) string {
+	var b strings.Builder
+	b.WriteString("Expected\n")
+	m.writeEvents(&b)
+	fmt.Fprintf(&b, "not to contain %d events in order", len(m.matchers))
+	return b.String()
+}
+
+func (m *eventsInOrderMatcher) writeEvents(b *strings.Builder) {
+	if len(m.events) == 0 {
+		b.WriteString("    <no events>\n")
+	}
+	for _, event := range m.events {
+		fmt.Fprintf(b, "    %s\n", describe(event))
+	}
+}
+
+func toMatcher(element interface{}) types.GomegaMatcher {
+	if matcher, ok := element.(types.GomegaMatcher); ok {
+		return matcher
+	}
+	if event, err := toEvent(element); err == nil {
+		return &eventMatcher{
+			description: fmt.Sprintf("to match %s", describe(event)),
+			match: func(e *base.Event) (bool, error) {
+				return e.Type == event.Type && strings.Join(e.Aggregate, ".") == strings.Join(event.Aggregate, "."), nil
+			},
+		}
+	}
+	return &eventMatcher{
+		match: func(e *base.Event) (bool, error) {
+			return false, fmt.Errorf("ContainEventsInOrder expects matchers or Events, got %T", element)
+		},
+	}
+}
+
+func describeElement(element interface{}, matcher types.GomegaMatcher) string {
+	if m, ok := matcher.(*eventMatcher); ok && m.description != "" {
+		return m.description
+	}
+	return fmt.Sprintf("%v", element)
+}
+
+func toEvents(actual interface{}) ([]*base.Event, error) {
+	switch a := actual.(type) {
+	case []*base.Event:
+		return a, nil
+	case []base.Event:
+		events := make([]*base.Event, len(a))
+		for i := range a {
+			events[i] = &a[i]
+		}
+		return events, nil
+	case base.EventStream:
+		return EventsOf(a, base.Select())()
+	default:
+		return nil, fmt.Errorf("expected Events or an EventStream, got %T", actual)
+	}
+}
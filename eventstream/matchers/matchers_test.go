@@ -0,0 +1,70 @@
+package matchers
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/memory"
+)
+
+var _ = Describe("Matchers", func() {
+	ev := &base.Event{
+		Sequence:  3,
+		Aggregate: []string{"orders", "42"},
+		Type:      "created",
+		Payload: map[string]interface{}{
+			"amount":   float64(120),
+			"customer": map[string]interface{}{"name": "Max"},
+		},
+	}
+
+	It("matches single Events", func() {
+		Expect(ev).To(HaveType("created"))
+		Expect(*ev).NotTo(HaveType("deleted"))
+		Expect(ev).To(HaveAggregate("orders", "42"))
+		Expect(ev).NotTo(HaveAggregate("orders"))
+		Expect(ev).To(MatchSelector("orders/created"))
+		Expect(ev).To(MatchSelector(base.Select(base.SelectAggregate("orders"))))
+		Expect(ev).NotTo(MatchSelector("payments"))
+	})
+
+	It("matches Payload fields", func() {
+		Expect(ev).To(HavePayloadField("amount", 120))
+		Expect(ev).To(HavePayloadField("amount", BeNumerically(">", 100)))
+		Expect(ev).To(HavePayloadField("customer.name", "Max"))
+		Expect(ev).NotTo(HavePayloadField("customer.email", "max@example.com"))
+		Expect(ev).NotTo(HavePayloadField("amount", "120"))
+	})
+
+	It("fails on non-Events", func() {
+		_, err := HaveType("created").Match("created")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("matches Events in order", func() {
+		events := []*base.Event{
+			{Sequence: 1, Aggregate: []string{"orders", "1"}, Type: "created"},
+			{Sequence: 2, Aggregate: []string{"orders", "2"}, Type: "created"},
+			{Sequence: 3, Aggregate: []string{"orders", "1"}, Type: "shipped"},
+		}
+		Expect(events).To(ContainEventsInOrder(HaveType("created"), HaveType("shipped")))
+		Expect(events).To(ContainEventsInOrder(&base.Event{Aggregate: []string{"orders", "1"}, Type: "shipped"}))
+		Expect(events).NotTo(ContainEventsInOrder(HaveType("shipped"), HaveType("created")))
+		matcher := ContainEventsInOrder(HaveType("shipped"), HaveType("created"))
+		matcher.Match(events)
+		Expect(matcher.FailureMessage(events)).To(ContainSubstring("3 » orders.1/shipped"))
+		Expect(matcher.FailureMessage(events)).To(ContainSubstring(`element 2 after matching 1:
+    to have type "created"`))
+	})
+
+	It("matches EventStreams eventually", func() {
+		stream := memory.NewEventStream()
+		go func() {
+			stream.Emit(&base.Event{Aggregate: []string{"orders", "1"}, Type: "created"})
+			stream.Emit(&base.Event{Aggregate: []string{"orders", "1"}, Type: "shipped"})
+		}()
+		Eventually(stream).Should(ContainEventsInOrder(HaveType("created"), HaveType("shipped")))
+		Eventually(EventsOf(stream, base.Select(base.SelectType("shipped")))).Should(HaveLen(1))
+	})
+})
@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("EventStream", func() {
+	liveConsumers := func(s *EventStream) int {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.consumers)
+	}
+
+	base.EventStreamSampleGroup(func() base.EventStream {
+		return NewEventStream()
+	})
+
+	It("resumes Subscriptions from the SequenceStore", func() {
+		sequences := NewSequenceStore()
+		Expect(sequences.Store("test", 1)).To(Succeed())
+		w := base.NewWrapper(NewEventStream(Sequences(sequences)))
+		w.Emit()
+		w.Emit()
+		received := make(chan int64, 2)
+		sub, err := w.Stream().Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error {
+			received <- e.Sequence
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(received).Should(Receive(Equal(int64(2))))
+		Expect(sub.Acknowledge(2)).To(Succeed())
+		Expect(sequences.Get("test")).To(Equal(int64(2)))
+	})
+
+	It("Listen only gets newly emitted Events", func() {
+		w := base.NewWrapper(NewEventStream())
+		w.Emit(w.Agg("test", "1"))
+		received := make(chan *base.Event, 100)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- w.Stream().Listen(ctx, base.Select(base.SelectAggregate("test")), func(e *base.Event) error {
+				received <- e
+				return nil
+			})
+		}()
+		Eventually(func() int {
+			w.Emit(w.Agg("other", "1"))
+			w.Emit(w.Agg("test", "2"))
+			return len(received)
+		}).ShouldNot(BeZero())
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		close(received)
+		for e := range received {
+			Expect(e.Sequence).To(BeNumerically(">", 1))
+			Expect(e.Aggregate).To(Equal([]string{"test", "2"}))
+		}
+	})
+
+	It("Subscription drops out of the live stream and catches up", func() {
+		stream := NewEventStream(BufferSize(1))
+		w := base.NewWrapper(stream)
+		entered := make(chan struct{}, 1)
+		release := make(chan struct{})
+		received := make(chan int64, 10)
+		sub, err := w.Stream().Subscribe(context.Background(), "slow", base.Select(), func(e *base.Event) error {
+			select {
+			case entered <- struct{}{}:
+			default:
+			}
+			<-release
+			received <- e.Sequence
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() int {
+			if liveConsumers(stream) > 0 {
+				w.Emit(w.DefAgg())
+			}
+			return len(entered)
+		}).Should(Equal(1))
+		for w.Stream().LastSequence() < 10 {
+			w.Emit(w.DefAgg())
+		}
+		close(release)
+		var sequences []int64
+		for len(sequences) < 10 {
+			sequences = append(sequences, <-received)
+		}
+		Expect(sequences).To(Equal([]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+		Expect(sub.DropOuts()).To(Equal(1))
+	})
+
+	It("rejects a second active Subscription with the same ID", func() {
+		s := NewEventStream()
+		_, err := s.Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error { return nil })
+		Expect(err).NotTo(HaveOccurred())
+		_, err = s.Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error { return nil })
+		Expect(err).To(Equal(base.ErrSubscriptionActive))
+	})
+
+	It("Wait returns the handler's error", func() {
+		clock := base.NewFakeClock(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+		w := base.NewWrapperWithClock(NewEventStream(Clock(clock)), clock)
+		w.Emit(w.IncrBy(time.Hour))
+		failure := errors.New("failure")
+		sub, _ := w.Stream().Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error {
+			return failure
+		})
+		Expect(sub.Wait()).To(Equal(failure))
+		Expect(sub.Active()).To(BeFalse())
+		Expect(sub.InactiveSince()).To(Equal(clock.Now()))
+	})
+
+	It("Shutdown removes the Subscription", func() {
+		s := NewEventStream()
+		sub, _ := s.Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error { return nil })
+		Expect(s.Acknowledge("test", 1)).To(Succeed())
+		sub.Shutdown()
+		Expect(sub.Active()).To(BeFalse())
+		Expect(s.Subscriptions()).To(BeEmpty())
+		Expect(s.Acknowledge("test", 1)).To(Equal(base.ErrSubscriptionNotFound))
+	})
+
+	It("keeps Subscriptions whose sequence can't be reset on Shutdown", func() {
+		sequences := &failingSequenceStore{SequenceStore: NewSequenceStore()}
+		s := NewEventStream(Sequences(sequences))
+		sub, _ := s.Subscribe(context.Background(), "test", base.Select(), func(e *base.Event) error { return nil })
+		Expect(s.Acknowledge("test", 1)).To(Succeed())
+		sequences.err = errors.New("failure")
+		sub.Shutdown()
+		Expect(sub.Wait()).To(Equal(sequences.err))
+		Expect(s.Subscriptions()).To(HaveLen(1))
+		Expect(sequences.Get("test")).To(Equal(int64(1)))
+
+		sequences.err = nil
+		sub.Shutdown()
+		Expect(s.Subscriptions()).To(BeEmpty())
+		Expect(sequences.Get("test")).To(BeZero())
+	})
+
+	It("returns ErrSequenceNotFound for unknown sequences", func() {
+		w := base.NewWrapper(NewEventStream())
+		w.Emit()
+		_, err := w.Stream().Get(2)
+		Expect(err).To(Equal(base.ErrSequenceNotFound))
+	})
+
+	It("resolves TimeRanges to Brackets", func() {
+		start := time.Date(2021, 3, 1, 13, 0, 0, 0, time.UTC)
+		w := base.NewWrapperWithStartTime(NewEventStream(), start)
+		for i := 0; i < 12; i++ {
+			w.Emit(w.IncrBy(10 * time.Minute))
+		}
+		bracket, err := base.ResolveTimeRange(w.Stream(), base.TimeRange{Since: start.Add(time.Hour), Until: start.Add(90 * time.Minute)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bracket).To(Equal(base.Range(6, 9)))
+	})
+})
+
+var _ = Describe("SequenceStore", func() {
+	base.SequenceStoreSampleGroup(func() base.SequenceStore {
+		return NewSequenceStore()
+	})
+})
+
+// failingSequenceStore fails to store sequences while err is set.
+type failingSequenceStore struct {
+	base.SequenceStore
+	err error
+}
+
+func (s *failingSequenceStore) Store(persistentClientID string, sequence int64) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.SequenceStore.Store(persistentClientID, sequence)
+}
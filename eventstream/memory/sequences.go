@@ -0,0 +1,28 @@
+package memory
+
+import "sync"
+
+// SequenceStore keeps the last acknowledged sequence of every persistent client in memory.
+type SequenceStore struct {
+	mutex     sync.Mutex
+	sequences map[string]int64
+}
+
+func NewSequenceStore() *SequenceStore {
+	return &SequenceStore{
+		sequences: make(map[string]int64),
+	}
+}
+
+func (s *SequenceStore) Get(persistentClientID string) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sequences[persistentClientID], nil
+}
+
+func (s *SequenceStore) Store(persistentClientID string, sequence int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sequences[persistentClientID] = sequence
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// EventStore keeps all Events in memory.
+type EventStore struct {
+	mutex  sync.RWMutex
+	events []*base.Event
+}
+
+func NewEventStore() *EventStore {
+	return &EventStore{}
+}
+
+func (s *EventStore) Store(event *base.Event) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	event.Sequence = int64(len(s.events)) + 1
+	s.events = append(s.events, event.Clone())
+	return event.Sequence, nil
+}
+
+func (s *EventStore) LastKnownSequence() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return int64(len(s.events))
+}
+
+func (s *EventStore) Read(sequence int64) (*base.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if sequence < 1 || sequence > int64(len(s.events)) {
+		return nil, base.ErrSequenceNotFound
+	}
+	return s.events[sequence-1].Clone(), nil
+}
+
+func (s *EventStore) ReadAll(ctx context.Context, sel base.Selector, bracket base.Bracket, handler base.EventHandler) error {
+	bracket.Sanitize(s.LastKnownSequence())
+	for sequence := bracket.NextSequence; sequence <= bracket.LastSequence; sequence++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.mutex.RLock()
+		event := s.events[sequence-1]
+		s.mutex.RUnlock()
+		if sel.Matches(event) {
+			if err := handler(event.Clone()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+const DefaultBufferSize = 256
+
+// EventStream is a fully concurrent base.EventStream. Live consumers get Events pushed into a buffer; a consumer
+// which can't keep up drops out of the live stream and catches up from the EventStore.
+type EventStream struct {
+	mutex         sync.Mutex
+	store         base.EventStore
+	sequences     base.SequenceStore
+	bufferSize    int
+	clock         base.Clock
+	consumers     map[*consumer]struct{}
+	subscriptions map[string]*Subscription
+}
+
+type Option = func(s *EventStream)
+
+func NewEventStream(opts ...Option) *EventStream {
+	s := &EventStream{
+		store:         NewEventStore(),
+		sequences:     NewSequenceStore(),
+		bufferSize:    DefaultBufferSize,
+		clock:         base.SystemClock,
+		consumers:     make(map[*consumer]struct{}),
+		subscriptions: make(map[string]*Subscription),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Storage replaces the in-memory EventStore, e.g. to make the EventStream durable.
+func Storage(store base.EventStore) Option {
+	return func(s *EventStream) {
+		s.store = store
+	}
+}
+
+// Sequences replaces the in-memory SequenceStore, so Subscriptions can be resumed after a restart.
+func Sequences(store base.SequenceStore) Option {
+	return func(s *EventStream) {
+		s.sequences = store
+	}
+}
+
+// BufferSize sets how many Events a live consumer may lag behind before it drops out of the live stream.
+func BufferSize(size int) Option {
+	return func(s *EventStream) {
+		s.bufferSize = size
+	}
+}
+
+// Clock replaces the SystemClock, which determines since when Subscriptions are inactive.
+func Clock(clock base.Clock) Option {
+	return func(s *EventStream) {
+		s.clock = clock
+	}
+}
+
+func (s *EventStream) Emit(event *base.Event) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sequence, err := s.store.Store(event)
+	if err != nil {
+		return 0, err
+	}
+	event.Sequence = sequence
+	for c := range s.consumers {
+		if !c.selector.Matches(event) {
+			continue
+		}
+		select {
+		case c.events <- event.Clone():
+		default:
+			close(c.events)
+			delete(s.consumers, c)
+		}
+	}
+	return sequence, nil
+}
+
+func (s *EventStream) LastSequence() int64 {
+	return s.store.LastKnownSequence()
+}
+
+func (s *EventStream) Get(sequence int64) (*base.Event, error) {
+	return s.store.Read(sequence)
+}
+
+func (s *EventStream) Stream(ctx context.Context, sel base.Selector, bracket base.Bracket, handler base.EventHandler) error {
+	return s.store.ReadAll(ctx, sel, bracket, handler)
+}
+
+func (s *EventStream) Listen(ctx context.Context, sel base.Selector, handler base.EventHandler) error {
+	c := &consumer{
+		selector: sel,
+		next:     s.LastSequence() + 1,
+		handler:  handler,
+	}
+	if err := s.consume(ctx, c); err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+func (s *EventStream) Subscribe(ctx context.Context, persistentClientID string, sel base.Selector, handler base.EventHandler) (base.Subscription, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sub, ok := s.subscriptions[persistentClientID]
+	if !ok {
+		lastAcknowledged, err := s.sequences.Get(persistentClientID)
+		if err != nil {
+			return nil, err
+		}
+		sub = &Subscription{
+			stream:           s,
+			persistentID:     persistentClientID,
+			lastAcknowledged: lastAcknowledged,
+		}
+		s.subscriptions[persistentClientID] = sub
+	}
+	if err := sub.start(ctx, sel, handler); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *EventStream) Acknowledge(persistentClientID string, sequence int64) error {
+	s.mutex.Lock()
+	sub, ok := s.subscriptions[persistentClientID]
+	s.mutex.Unlock()
+	if !ok {
+		return base.ErrSubscriptionNotFound
+	}
+	return sub.Acknowledge(sequence)
+}
+
+func (s *EventStream) Subscriptions() []base.Subscription {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	subscriptions := make([]base.Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions
+}
+
+func (s *EventStream) removeSubscription(sub *Subscription) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.subscriptions[sub.persistentID] == sub {
+		delete(s.subscriptions, sub.persistentID)
+	}
+}
+
+type consumer struct {
+	selector base.Selector
+	next     int64
+	handler  base.EventHandler
+	events   chan *base.Event
+	dropOut  func()
+}
+
+// consume delivers all Events starting at the consumer's next sequence until the context is cancelled or the handler
+// fails. It alternates between catching up from the EventStore and receiving live Events.
+func (s *EventStream) consume(ctx context.Context, c *consumer) error {
+	defer s.unregister(c)
+	for {
+		if err := s.catchUp(ctx, c); err != nil {
+			return err
+		}
+		for live := true; live; {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case event, ok := <-c.events:
+				if !ok {
+					if c.dropOut != nil {
+						c.dropOut()
+					}
+					live = false
+					break
+				}
+				if event.Sequence < c.next {
+					continue
+				}
+				c.next = event.Sequence + 1
+				if err := c.handler(event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// catchUp reads Events from the EventStore until the consumer has reached the end of the stream and has been
+// registered as a live consumer.
+func (s *EventStream) catchUp(ctx context.Context, c *consumer) error {
+	for {
+		last := s.store.LastKnownSequence()
+		if c.next <= last {
+			err := s.store.ReadAll(ctx, c.selector, base.Range(c.next, last), func(e *base.Event) error {
+				c.next = e.Sequence + 1
+				return c.handler(e)
+			})
+			if err != nil {
+				return err
+			}
+			c.next = last + 1
+		}
+		s.mutex.Lock()
+		if s.store.LastKnownSequence() < c.next {
+			c.events = make(chan *base.Event, s.bufferSize)
+			s.consumers[c] = struct{}{}
+			s.mutex.Unlock()
+			return nil
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func (s *EventStream) unregister(c *consumer) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.consumers, c)
+}
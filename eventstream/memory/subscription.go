@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// Subscription is a persistent base.Subscription of an EventStream. It resumes after the last acknowledged sequence
+// (as kept by the EventStream's SequenceStore) whenever it is re-attached.
+type Subscription struct {
+	stream       *EventStream
+	persistentID string
+
+	mutex            sync.Mutex
+	selector         base.Selector
+	lastAcknowledged int64
+	active           bool
+	inactiveSince    time.Time
+	dropOuts         int
+	cancel           context.CancelFunc
+	done             chan struct{}
+	err              error
+}
+
+func (s *Subscription) start(ctx context.Context, sel base.Selector, handler base.EventHandler) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.active {
+		return base.ErrSubscriptionActive
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.selector = sel
+	s.active = true
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.err = nil
+	c := &consumer{
+		selector: sel,
+		next:     s.lastAcknowledged + 1,
+		handler:  handler,
+		dropOut:  s.droppedOut,
+	}
+	go s.run(ctx, c, s.done)
+	return nil
+}
+
+func (s *Subscription) run(ctx context.Context, c *consumer, done chan struct{}) {
+	err := s.stream.consume(ctx, c)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err != context.Canceled {
+		s.err = err
+	}
+	s.cancel()
+	s.active = false
+	s.inactiveSince = s.stream.clock.Now()
+	close(done)
+}
+
+func (s *Subscription) droppedOut() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dropOuts++
+}
+
+func (s *Subscription) PersistentID() string {
+	return s.persistentID
+}
+
+func (s *Subscription) ActiveSelector() base.Selector {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.selector
+}
+
+func (s *Subscription) LastAcknowledgedSequence() (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastAcknowledged, nil
+}
+
+func (s *Subscription) Acknowledge(sequence int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sequence <= s.lastAcknowledged {
+		return nil
+	}
+	if err := s.stream.sequences.Store(s.persistentID, sequence); err != nil {
+		return err
+	}
+	s.lastAcknowledged = sequence
+	return nil
+}
+
+func (s *Subscription) Active() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.active
+}
+
+func (s *Subscription) InactiveSince() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.inactiveSince
+}
+
+func (s *Subscription) Wait() error {
+	s.mutex.Lock()
+	done := s.done
+	s.mutex.Unlock()
+	if done == nil {
+		return nil
+	}
+	<-done
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+func (s *Subscription) DropOuts() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dropOuts
+}
+
+// Shutdown detaches the Subscription, resets its sequence in the SequenceStore and removes it from the EventStream. If
+// the sequence can't be reset, the Subscription stays known to the EventStream and Wait returns the error, so it
+// isn't resumed at the old sequence unnoticed.
+func (s *Subscription) Shutdown() {
+	s.mutex.Lock()
+	cancel := s.cancel
+	s.mutex.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.Wait()
+	if err := s.stream.sequences.Store(s.persistentID, 0); err != nil {
+		s.mutex.Lock()
+		s.err = err
+		s.mutex.Unlock()
+		return
+	}
+	s.stream.removeSubscription(s)
+}
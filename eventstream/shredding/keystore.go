@@ -0,0 +1,190 @@
+package shredding
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const keySize = 32
+
+var (
+	ErrKeyNotFound = errors.New("data key not found")
+	// ErrForgotten is returned by KeyStore.Key for Aggregates whose data key has been deleted.
+	ErrForgotten = errors.New("aggregate has been forgotten")
+)
+
+// KeyStore manages the data keys used to encrypt Payload fields, one per Aggregate.
+type KeyStore interface {
+	// Key returns the data key of the given Aggregate, creating it if necessary, or ErrForgotten if it has been
+	// deleted.
+	Key(aggregate string) ([]byte, error)
+	// Lookup returns the data key of the given Aggregate or ErrKeyNotFound if it doesn't exist (anymore).
+	Lookup(aggregate string) ([]byte, error)
+	// Delete removes the data key of the given Aggregate, rendering all its encrypted fields unreadable. It leaves a
+	// tombstone, so no new data key is created for the Aggregate.
+	Delete(aggregate string) error
+}
+
+// MemoryKeyStore keeps the data keys in memory. Deleted keys are kept as nil.
+type MemoryKeyStore struct {
+	mutex sync.Mutex
+	keys  map[string][]byte
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{
+		keys: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryKeyStore) Key(aggregate string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if key, ok := s.keys[aggregate]; ok {
+		if key == nil {
+			return nil, ErrForgotten
+		}
+		return key, nil
+	}
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	s.keys[aggregate] = key
+	return key, nil
+}
+
+func (s *MemoryKeyStore) Lookup(aggregate string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if key := s.keys[aggregate]; key != nil {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (s *MemoryKeyStore) Delete(aggregate string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[aggregate] = nil
+	return nil
+}
+
+// FileKeyStore keeps one file per data key in a directory. Deleting a key replaces its file with an empty one as
+// tombstone. Several processes can share the directory.
+type FileKeyStore struct {
+	mutex     sync.Mutex
+	directory string
+}
+
+func NewFileKeyStore(directory string) (*FileKeyStore, error) {
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, err
+	}
+	return &FileKeyStore{
+		directory: directory,
+	}, nil
+}
+
+func (s *FileKeyStore) Key(aggregate string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if key, err := s.read(aggregate); err != ErrKeyNotFound {
+		return s.usable(key, err)
+	}
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := s.writeTemp(key)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	// Linking fails instead of replacing a key which another process has created in the meantime
+	if err := os.Link(tmp, s.filename(aggregate)); os.IsExist(err) {
+		return s.usable(s.read(aggregate))
+	} else if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *FileKeyStore) Lookup(aggregate string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key, err := s.read(aggregate)
+	if err == nil && len(key) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return key, err
+}
+
+func (s *FileKeyStore) Delete(aggregate string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	tmp, err := s.writeTemp(nil)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	return os.Rename(tmp, s.filename(aggregate))
+}
+
+func (s *FileKeyStore) read(aggregate string) ([]byte, error) {
+	key, err := ioutil.ReadFile(s.filename(aggregate))
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
+	return key, err
+}
+
+// usable turns the tombstone of a deleted key into ErrForgotten.
+func (s *FileKeyStore) usable(key []byte, err error) ([]byte, error) {
+	if err == nil && len(key) == 0 {
+		return nil, ErrForgotten
+	}
+	return key, err
+}
+
+// writeTemp writes the data into a new temporary file in the directory and returns its name.
+func (s *FileKeyStore) writeTemp(data []byte) (string, error) {
+	tmp, err := ioutil.TempFile(s.directory, ".key-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func (s *FileKeyStore) filename(aggregate string) string {
+	hash := sha256.Sum256([]byte(aggregate))
+	return filepath.Join(s.directory, hex.EncodeToString(hash[:])+".key")
+}
+
+func generateKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,188 @@
+package shredding
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+const (
+	// EncryptedField is the key of the object replacing an encrypted Payload field.
+	EncryptedField = "$encrypted"
+	// Redacted replaces encrypted Payload fields whose data key has been deleted.
+	Redacted = "<redacted>"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Shredder encrypts configured Payload fields with a per-Aggregate data key. Deleting that key ("forgetting" the
+// Aggregate) makes those fields permanently unreadable, although the Events themselves stay in the stream. It can be
+// registered as client.Middleware via client.Use.
+type Shredder struct {
+	keys  KeyStore
+	rules []fieldRule
+}
+
+type fieldRule struct {
+	selector base.Selector
+	fields   []string
+}
+
+type Option = func(s *Shredder)
+
+func NewShredder(keys KeyStore, opts ...Option) *Shredder {
+	s := &Shredder{
+		keys: keys,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// EncryptFields encrypts the given Payload fields of all Events.
+func EncryptFields(fields ...string) Option {
+	return EncryptFieldsOf(base.Select(), fields...)
+}
+
+// EncryptFieldsOf encrypts the given Payload fields of all Events matching the Selector.
+func EncryptFieldsOf(sel base.Selector, fields ...string) Option {
+	return func(s *Shredder) {
+		s.rules = append(s.rules, fieldRule{
+			selector: sel,
+			fields:   fields,
+		})
+	}
+}
+
+func (s *Shredder) Outgoing(ctx context.Context, event *base.Event) error {
+	return s.Encrypt(event)
+}
+
+func (s *Shredder) Incoming(ctx context.Context, event *base.Event) error {
+	return s.Decrypt(event)
+}
+
+// Forget deletes the data key of the given Aggregate. Configured fields of Events emitted for it afterwards are
+// replaced with Redacted instead of being encrypted with a new key.
+func (s *Shredder) Forget(aggregate ...string) error {
+	return s.keys.Delete(strings.Join(aggregate, "."))
+}
+
+// Encrypt replaces all configured Payload fields of the Event with their encrypted form, or with Redacted if the
+// Aggregate has been forgotten.
+func (s *Shredder) Encrypt(event *base.Event) error {
+	var gcm cipher.AEAD
+	forgotten := false
+	aggregate := strings.Join(event.Aggregate, ".")
+	for _, rule := range s.rules {
+		if !rule.selector.Matches(event) {
+			continue
+		}
+		for _, field := range rule.fields {
+			value, ok := event.Payload[field]
+			if !ok || isEncrypted(value) {
+				continue
+			}
+			if forgotten {
+				event.Payload[field] = Redacted
+				continue
+			}
+			if gcm == nil {
+				key, err := s.keys.Key(aggregate)
+				if err == ErrForgotten {
+					forgotten = true
+					event.Payload[field] = Redacted
+					continue
+				} else if err != nil {
+					return err
+				}
+				if gcm, err = newGCM(key); err != nil {
+					return err
+				}
+			}
+			plaintext, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("encrypting field %s: %w", field, err)
+			}
+			nonce := make([]byte, gcm.NonceSize())
+			if _, err := rand.Read(nonce); err != nil {
+				return err
+			}
+			ciphertext := gcm.Seal(nonce, nonce, plaintext, []byte(aggregate))
+			event.Payload[field] = map[string]interface{}{
+				EncryptedField: base64.StdEncoding.EncodeToString(ciphertext),
+			}
+		}
+	}
+	return nil
+}
+
+// Decrypt restores all encrypted Payload fields of the Event. Fields whose data key has been deleted are replaced
+// with Redacted.
+func (s *Shredder) Decrypt(event *base.Event) error {
+	var gcm cipher.AEAD
+	forgotten := false
+	aggregate := strings.Join(event.Aggregate, ".")
+	for field, value := range event.Payload {
+		if !isEncrypted(value) {
+			continue
+		}
+		if forgotten {
+			event.Payload[field] = Redacted
+			continue
+		}
+		if gcm == nil {
+			key, err := s.keys.Lookup(aggregate)
+			if err == ErrKeyNotFound {
+				forgotten = true
+				event.Payload[field] = Redacted
+				continue
+			} else if err != nil {
+				return err
+			}
+			if gcm, err = newGCM(key); err != nil {
+				return err
+			}
+		}
+		encoded, _ := value.(map[string]interface{})[EncryptedField].(string)
+		ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(ciphertext) < gcm.NonceSize() {
+			return fmt.Errorf("decrypting field %s of event %d: %w", field, event.Sequence, ErrInvalidCiphertext)
+		}
+		plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], []byte(aggregate))
+		if err != nil {
+			return fmt.Errorf("decrypting field %s of event %d: %w", field, event.Sequence, err)
+		}
+		var decrypted interface{}
+		if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+			return fmt.Errorf("decrypting field %s of event %d: %w", field, event.Sequence, err)
+		}
+		event.Payload[field] = decrypted
+	}
+	return nil
+}
+
+func isEncrypted(value interface{}) bool {
+	if m, ok := value.(map[string]interface{}); ok && len(m) == 1 {
+		_, ok := m[EncryptedField].(string)
+		return ok
+	}
+	return false
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
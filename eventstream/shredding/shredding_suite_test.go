@@ -0,0 +1,13 @@
+package shredding
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestShredding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Shredding Suite")
+}
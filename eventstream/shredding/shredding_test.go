@@ -0,0 +1,142 @@
+package shredding
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Shredding", func() {
+	var directory string
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "shredding")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(directory)
+	})
+
+	newEvent := func() *base.Event {
+		return &base.Event{
+			Aggregate: []string{"customers", "42"},
+			Type:      "registered",
+			Payload:   map[string]interface{}{"email": "max@example.com", "age": float64(42), "plan": "free"},
+		}
+	}
+
+	for name, factory := range map[string]func() KeyStore{
+		"MemoryKeyStore": func() KeyStore { return NewMemoryKeyStore() },
+		"FileKeyStore": func() KeyStore {
+			keys, err := NewFileKeyStore(directory)
+			Expect(err).NotTo(HaveOccurred())
+			return keys
+		},
+	} {
+		factory := factory
+		Context(name, func() {
+			It("encrypts configured fields and decrypts them again", func() {
+				s := NewShredder(factory(), EncryptFields("email", "age"))
+				ev := newEvent()
+				Expect(s.Encrypt(ev)).To(Succeed())
+				Expect(ev.Payload["email"]).To(HaveKey(EncryptedField))
+				Expect(ev.Payload["age"]).To(HaveKey(EncryptedField))
+				Expect(ev.Payload["plan"]).To(Equal("free"))
+				Expect(s.Decrypt(ev)).To(Succeed())
+				Expect(ev.Payload).To(Equal(newEvent().Payload))
+			})
+			It("redacts fields after the Aggregate has been forgotten", func() {
+				s := NewShredder(factory(), EncryptFields("email"))
+				ev := newEvent()
+				Expect(s.Encrypt(ev)).To(Succeed())
+				Expect(s.Forget("customers", "42")).To(Succeed())
+				Expect(s.Decrypt(ev)).To(Succeed())
+				Expect(ev.Payload["email"]).To(Equal(Redacted))
+				Expect(ev.Payload["plan"]).To(Equal("free"))
+			})
+			It("redacts fields of Events emitted after the Aggregate has been forgotten", func() {
+				keys := factory()
+				s := NewShredder(keys, EncryptFields("email"))
+				before := newEvent()
+				Expect(s.Encrypt(before)).To(Succeed())
+				Expect(s.Forget("customers", "42")).To(Succeed())
+				_, err := keys.Key("customers.42")
+				Expect(err).To(Equal(ErrForgotten))
+				after := newEvent()
+				Expect(s.Encrypt(after)).To(Succeed())
+				Expect(after.Payload["email"]).To(Equal(Redacted))
+				Expect(s.Decrypt(before)).To(Succeed())
+				Expect(before.Payload["email"]).To(Equal(Redacted))
+			})
+		})
+	}
+
+	It("keeps the data key another FileKeyStore has created in the meantime", func() {
+		first, err := NewFileKeyStore(directory)
+		Expect(err).NotTo(HaveOccurred())
+		second, err := NewFileKeyStore(directory)
+		Expect(err).NotTo(HaveOccurred())
+		keys := make([][]byte, 20)
+		var wg sync.WaitGroup
+		for i := range keys {
+			wg.Add(1)
+			go func(i int, store KeyStore) {
+				defer wg.Done()
+				keys[i], _ = store.Key("customers.42")
+			}(i, []KeyStore{first, second}[i%2])
+		}
+		wg.Wait()
+		stored, err := first.Lookup("customers.42")
+		Expect(err).NotTo(HaveOccurred())
+		for _, key := range keys {
+			Expect(key).To(Equal(stored))
+		}
+	})
+
+	It("streams Events of a forgotten Aggregate which has been written again", func() {
+		srv := clienttest.NewServer()
+		defer srv.Close()
+		s := NewShredder(NewMemoryKeyStore(), EncryptFields("email"))
+		cl := srv.Client(client.Use(s))
+		_, err := cl.Emit(context.Background(), *newEvent())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Forget("customers", "42")).To(Succeed())
+		_, err = cl.Emit(context.Background(), *newEvent())
+		Expect(err).NotTo(HaveOccurred())
+
+		var emails []interface{}
+		_, err = cl.Stream(context.Background(), &base.Selector{}, &base.Bracket{}, func(e *base.Event) error {
+			emails = append(emails, e.Payload["email"])
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(emails).To(Equal([]interface{}{Redacted, Redacted}))
+		Expect(srv.Emitted()[1].Payload["email"]).To(Equal(Redacted))
+	})
+
+	It("only encrypts fields of selected Events", func() {
+		s := NewShredder(NewMemoryKeyStore(), EncryptFieldsOf(base.Select(base.SelectAggregate("orders")), "email"))
+		ev := newEvent()
+		Expect(s.Encrypt(ev)).To(Succeed())
+		Expect(ev.Payload).To(Equal(newEvent().Payload))
+	})
+	It("rejects ciphertext bound to another Aggregate", func() {
+		s := NewShredder(NewMemoryKeyStore(), EncryptFields("email"))
+		ev := newEvent()
+		Expect(s.Encrypt(ev)).To(Succeed())
+		other := newEvent()
+		other.Aggregate = []string{"customers", "43"}
+		Expect(s.Encrypt(other)).To(Succeed())
+		other.Payload["email"] = ev.Payload["email"]
+		Expect(s.Decrypt(other)).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,126 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// diffEvents returns one line per differing position, or nothing if the Events match.
+func diffEvents(expected []base.Event, actual []*base.Event) []string {
+	var diff []string
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			diff = append(diff, fmt.Sprintf("  #%d missing:    %s", i+1, describe(&expected[i])))
+		case i >= len(expected):
+			diff = append(diff, fmt.Sprintf("  #%d unexpected: %s", i+1, describe(actual[i])))
+		case !eventMatches(&expected[i], actual[i]):
+			diff = append(diff,
+				fmt.Sprintf("  #%d expected:   %s", i+1, describe(&expected[i])),
+				fmt.Sprintf("  #%d actual:     %s", i+1, describe(actual[i])))
+		}
+	}
+	return diff
+}
+
+func eventMatches(expected, actual *base.Event) bool {
+	if expected.Type != actual.Type || strings.Join(expected.Aggregate, ".") != strings.Join(actual.Aggregate, ".") {
+		return false
+	}
+	if !expected.OccurredAt.IsZero() && !expected.OccurredAt.Equal(actual.OccurredAt) {
+		return false
+	}
+	if len(expected.Payload) == 0 && len(actual.Payload) == 0 {
+		return true
+	}
+	e, _ := normalize(expected.Payload)
+	a, _ := normalize(actual.Payload)
+	return reflect.DeepEqual(e, a)
+}
+
+func describe(e *base.Event) string {
+	s := fmt.Sprintf("%s/%s", strings.Join(e.Aggregate, "."), e.Type)
+	if len(e.Payload) > 0 {
+		payload, _ := json.Marshal(e.Payload)
+		s += " » " + string(payload)
+	}
+	return s
+}
+
+func describeAll(events []*base.Event) []string {
+	lines := make([]string, len(events))
+	for i, e := range events {
+		lines[i] = "    " + describe(e)
+	}
+	return lines
+}
+
+// diffState compares both states in their JSON form and returns a line diff if they differ.
+func diffState(expected, actual interface{}) ([]string, error) {
+	e, err := normalize(expected)
+	if err != nil {
+		return nil, fmt.Errorf("encoding expected state: %w", err)
+	}
+	a, err := normalize(actual)
+	if err != nil {
+		return nil, fmt.Errorf("encoding actual state: %w", err)
+	}
+	if reflect.DeepEqual(e, a) {
+		return nil, nil
+	}
+	expectedJSON, _ := json.MarshalIndent(e, "", "  ")
+	actualJSON, _ := json.MarshalIndent(a, "", "  ")
+	return diffLines(strings.Split(string(expectedJSON), "\n"), strings.Split(string(actualJSON), "\n")), nil
+}
+
+// normalize converts the value into its generic JSON representation, so that e.g. structs and maps or ints and
+// floats compare equal.
+func normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+// diffLines returns a unified line diff based on the longest common subsequence of both texts.
+func diffLines(expected, actual []string) []string {
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var diff []string
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			diff = append(diff, "  "+expected[i])
+			i++
+			j++
+		case j < len(actual) && (i == len(expected) || lcs[i][j+1] > lcs[i+1][j]):
+			diff = append(diff, "+ "+actual[j])
+			j++
+		default:
+			diff = append(diff, "- "+expected[i])
+			i++
+		}
+	}
+	return diff
+}
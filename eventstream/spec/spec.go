@@ -0,0 +1,291 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega/types"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+const DefaultTimeout = time.Second
+
+// pollInterval is how often asynchronous units are checked for the expected outcome.
+const pollInterval = 10 * time.Millisecond
+
+// CommandHandler decides which new Events result from handling the command, given the Aggregate's history.
+type CommandHandler func(history []*base.Event, command interface{}) ([]*base.Event, error)
+
+// Specification describes a unit under test: an Aggregate, a Projection or a whole EventStream. Scenarios for it are
+// started with Given.
+type Specification struct {
+	fail    types.GomegaFailHandler
+	timeout time.Duration
+	clock   base.Clock
+	state   func() interface{}
+	// run feeds the given Events and the input of When to the unit under test and returns the new Events.
+	run func(given []*base.Event, input interface{}) ([]*base.Event, error)
+	// emitted returns all Events emitted after the given sequence so far, for units which work asynchronously.
+	emitted func(after int64) ([]*base.Event, error)
+	// lastSequence returns the sequence the given Events are numbered after, for units backed by an EventStream
+	// which may already contain Events.
+	lastSequence func() int64
+}
+
+type Option = func(s *Specification)
+
+// FailHandler replaces ginkgo.Fail, e.g. to use the DSL in plain Go tests.
+func FailHandler(fail types.GomegaFailHandler) Option {
+	return func(s *Specification) {
+		s.fail = fail
+	}
+}
+
+// Timeout sets how long asynchronous units get to produce the expected outcome, and how long Then watches them for
+// unexpected Events when none are expected.
+func Timeout(timeout time.Duration) Option {
+	return func(s *Specification) {
+		s.timeout = timeout
+	}
+}
+
+// Clock replaces the SystemClock, which measures the Timeout.
+func Clock(clock base.Clock) Option {
+	return func(s *Specification) {
+		s.clock = clock
+	}
+}
+
+// State registers a function returning the read model, to be checked with ThenState.
+func State(state func() interface{}) Option {
+	return func(s *Specification) {
+		s.state = state
+	}
+}
+
+func newSpecification(opts []Option) *Specification {
+	s := &Specification{
+		fail:    ginkgo.Fail,
+		timeout: DefaultTimeout,
+		clock:   base.SystemClock,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Aggregate specifies a CommandHandler: When takes a command and Then the Events it decides on.
+func Aggregate(handler CommandHandler, opts ...Option) *Specification {
+	s := newSpecification(opts)
+	s.run = func(given []*base.Event, command interface{}) ([]*base.Event, error) {
+		return handler(given, command)
+	}
+	return s
+}
+
+// Projection specifies an EventHandler building a read model: When takes a new Event, ThenState the expected read
+// model as returned by the State function.
+func Projection(handler base.EventHandler, state func() interface{}, opts ...Option) *Specification {
+	s := newSpecification(append([]Option{State(state)}, opts...))
+	s.run = func(given []*base.Event, input interface{}) ([]*base.Event, error) {
+		for _, event := range given {
+			if err := handler(event); err != nil {
+				return nil, fmt.Errorf("handling given event %d: %w", event.Sequence, err)
+			}
+		}
+		if input == nil {
+			return nil, nil
+		}
+		event, ok := toEvent(input)
+		if !ok {
+			return nil, fmt.Errorf("When expects an Event for projections, got %T", input)
+		}
+		event.Sequence = int64(len(given)) + 1
+		return nil, handler(event)
+	}
+	return s
+}
+
+// Stream specifies the behaviour of everything connected to the EventStream (e.g. the one of a clienttest.Server).
+// Given Events are emitted into the stream, When takes an Event to emit or a func() error to run, and Then waits for
+// the expected Events to be emitted after the given ones. The stream doesn't have to be empty, so it can be seeded
+// beforehand or shared by several scenarios.
+func Stream(stream base.EventStream, opts ...Option) *Specification {
+	s := newSpecification(opts)
+	s.run = func(given []*base.Event, input interface{}) ([]*base.Event, error) {
+		for _, event := range given {
+			if _, err := stream.Emit(event.Clone()); err != nil {
+				return nil, fmt.Errorf("emitting given event: %w", err)
+			}
+		}
+		switch in := input.(type) {
+		case nil:
+			return nil, nil
+		case func() error:
+			return nil, in()
+		default:
+			event, ok := toEvent(input)
+			if !ok {
+				return nil, fmt.Errorf("When expects an Event or a func() error for streams, got %T", input)
+			}
+			_, err := stream.Emit(event)
+			return nil, err
+		}
+	}
+	s.lastSequence = stream.LastSequence
+	s.emitted = func(after int64) ([]*base.Event, error) {
+		var events []*base.Event
+		err := stream.Stream(context.Background(), base.Select(), base.From(after+1), func(e *base.Event) error {
+			events = append(events, e)
+			return nil
+		})
+		return events, err
+	}
+	return s
+}
+
+// Scenario is a single Given/When/Then case of a Specification.
+type Scenario struct {
+	spec  *Specification
+	given []*base.Event
+	input interface{}
+}
+
+// Given starts a Scenario with the given past Events (base.Event or *base.Event).
+func (s *Specification) Given(events ...interface{}) *Scenario {
+	sc := &Scenario{
+		spec: s,
+	}
+	for _, e := range events {
+		event, ok := toEvent(e)
+		if !ok {
+			s.fail(fmt.Sprintf("Given expects Events, got %T", e), 1)
+			return sc
+		}
+		event.Sequence = int64(len(sc.given)) + 1
+		sc.given = append(sc.given, event)
+	}
+	return sc
+}
+
+// When sets the command, Event or action under test.
+func (sc *Scenario) When(input interface{}) *Scenario {
+	sc.input = input
+	return sc
+}
+
+// Then asserts that exactly the expected Events resulted from When. Events are compared by Aggregate, Type and
+// Payload; OccurredAt only if it is set in the expected Event. For asynchronous units, Then without Events waits for
+// the whole Timeout, so Events emitted late are caught as well.
+func (sc *Scenario) Then(expected ...base.Event) {
+	given, offset := sc.cloneGiven()
+	actual, err := sc.spec.run(given, sc.input)
+	if err != nil {
+		sc.spec.fail(fmt.Sprintf("Then: expected %d events, but got error: %s", len(expected), err), 1)
+		return
+	}
+	if sc.spec.emitted != nil {
+		actual, err = sc.eventually(func() ([]*base.Event, bool, error) {
+			events, err := sc.spec.emitted(offset + int64(len(given)))
+			if len(expected) == 0 {
+				// Nothing to wait for, so only the first unexpected Event ends the Timeout early
+				return events, len(events) > 0, err
+			}
+			return events, len(diffEvents(expected, events)) == 0, err
+		})
+		if err != nil {
+			sc.spec.fail(fmt.Sprintf("Then: reading emitted events: %s", err), 1)
+			return
+		}
+	}
+	if diff := diffEvents(expected, actual); len(diff) > 0 {
+		sc.spec.fail("Then: expected events differ\n"+strings.Join(diff, "\n"), 1)
+	}
+}
+
+// ThenError asserts that When failed with the expected error (compared with errors.Is or by message).
+func (sc *Scenario) ThenError(expected error) {
+	given, _ := sc.cloneGiven()
+	actual, err := sc.spec.run(given, sc.input)
+	if err == nil {
+		sc.spec.fail(fmt.Sprintf("ThenError: expected error %q, but got %d events:\n%s", expected, len(actual), strings.Join(describeAll(actual), "\n")), 1)
+		return
+	}
+	if !errors.Is(err, expected) && err.Error() != expected.Error() {
+		sc.spec.fail(fmt.Sprintf("ThenError: expected error\n    %q\nbut got\n    %q", expected, err), 1)
+	}
+}
+
+// ThenState asserts that the read model equals the expected state after When. Both are compared in their JSON form.
+func (sc *Scenario) ThenState(expected interface{}) {
+	if sc.spec.state == nil {
+		sc.spec.fail("ThenState: the Specification has no State function", 1)
+		return
+	}
+	given, _ := sc.cloneGiven()
+	if _, err := sc.spec.run(given, sc.input); err != nil {
+		sc.spec.fail(fmt.Sprintf("ThenState: expected state, but got error: %s", err), 1)
+		return
+	}
+	var diff []string
+	check := func() ([]*base.Event, bool, error) {
+		var err error
+		diff, err = diffState(expected, sc.spec.state())
+		return nil, len(diff) == 0, err
+	}
+	if sc.spec.emitted != nil {
+		if _, err := sc.eventually(check); err != nil {
+			sc.spec.fail(fmt.Sprintf("ThenState: %s", err), 1)
+			return
+		}
+	} else if _, _, err := check(); err != nil {
+		sc.spec.fail(fmt.Sprintf("ThenState: %s", err), 1)
+		return
+	}
+	if len(diff) > 0 {
+		sc.spec.fail("ThenState: expected state differs (- expected, + actual)\n"+strings.Join(diff, "\n"), 1)
+	}
+}
+
+// eventually polls until the check succeeds or the timeout elapses and returns the last result.
+func (sc *Scenario) eventually(check func() ([]*base.Event, bool, error)) ([]*base.Event, error) {
+	deadline := sc.spec.clock.Now().Add(sc.spec.timeout)
+	for {
+		events, ok, err := check()
+		if ok || err != nil || !sc.spec.clock.Now().Before(deadline) {
+			return events, err
+		}
+		sc.spec.clock.Sleep(pollInterval)
+	}
+}
+
+// cloneGiven returns copies of the given Events, numbered after the last sequence of the unit under test, and that
+// sequence.
+func (sc *Scenario) cloneGiven() ([]*base.Event, int64) {
+	var offset int64
+	if sc.spec.lastSequence != nil {
+		offset = sc.spec.lastSequence()
+	}
+	given := make([]*base.Event, len(sc.given))
+	for i, event := range sc.given {
+		given[i] = event.Clone()
+		given[i].Sequence = offset + int64(i) + 1
+	}
+	return given, offset
+}
+
+func toEvent(value interface{}) (*base.Event, bool) {
+	switch e := value.(type) {
+	case base.Event:
+		return e.Clone(), true
+	case *base.Event:
+		return e.Clone(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,246 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/client/clienttest"
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/memory"
+)
+
+var errAlreadyShipped = errors.New("order already shipped")
+
+func order(t string, payload map[string]interface{}) base.Event {
+	return base.Event{Aggregate: []string{"orders", "1"}, Type: t, Payload: payload}
+}
+
+// decide is a tiny order Aggregate: it can be shipped exactly once.
+func decide(history []*base.Event, command interface{}) ([]*base.Event, error) {
+	for _, e := range history {
+		if e.Type == "shipped" {
+			return nil, errAlreadyShipped
+		}
+	}
+	shipped := order("shipped", map[string]interface{}{"carrier": command})
+	return []*base.Event{&shipped}, nil
+}
+
+type recorder struct {
+	failures []string
+}
+
+func (r *recorder) fail(message string, _ ...int) {
+	r.failures = append(r.failures, message)
+}
+
+var _ = Describe("Specification", func() {
+	var rec *recorder
+
+	BeforeEach(func() {
+		rec = &recorder{}
+	})
+
+	Context("Aggregate", func() {
+		It("passes when the expected events are decided", func() {
+			Aggregate(decide, FailHandler(rec.fail)).
+				Given(order("created", nil)).
+				When("ups").
+				Then(order("shipped", map[string]interface{}{"carrier": "ups"}))
+			Expect(rec.failures).To(BeEmpty())
+		})
+
+		It("reports differing events line by line", func() {
+			Aggregate(decide, FailHandler(rec.fail)).
+				Given(order("created", nil)).
+				When("dhl").
+				Then(order("shipped", map[string]interface{}{"carrier": "ups"}), order("invoiced", nil))
+			Expect(rec.failures).To(ConsistOf(And(
+				ContainSubstring(`#1 expected:   orders.1/shipped » {"carrier":"ups"}`),
+				ContainSubstring(`#1 actual:     orders.1/shipped » {"carrier":"dhl"}`),
+				ContainSubstring(`#2 missing:    orders.1/invoiced`),
+			)))
+		})
+
+		It("checks expected errors", func() {
+			spec := Aggregate(decide, FailHandler(rec.fail))
+			spec.Given(order("created", nil), order("shipped", nil)).When("ups").ThenError(errAlreadyShipped)
+			Expect(rec.failures).To(BeEmpty())
+			spec.Given(order("created", nil)).When("ups").ThenError(errAlreadyShipped)
+			Expect(rec.failures).To(ConsistOf(ContainSubstring(`expected error "order already shipped", but got 1 events`)))
+		})
+
+		It("does not leak changes to the given events into later runs", func() {
+			spec := Aggregate(func(history []*base.Event, command interface{}) ([]*base.Event, error) {
+				history[0].Type = "changed"
+				return nil, nil
+			}, FailHandler(rec.fail))
+			scenario := spec.Given(order("created", nil)).When(nil)
+			scenario.Then()
+			scenario.Then()
+			Expect(scenario.given[0].Type).To(Equal("created"))
+		})
+	})
+
+	Context("Projection", func() {
+		var totals map[string]int
+
+		handler := func(e *base.Event) error {
+			if e.Type == "broken" {
+				return errors.New("cannot project")
+			}
+			totals[e.Type]++
+			return nil
+		}
+
+		BeforeEach(func() {
+			totals = make(map[string]int)
+		})
+
+		It("compares the read model", func() {
+			Projection(handler, func() interface{} { return totals }, FailHandler(rec.fail)).
+				Given(order("created", nil), order("shipped", nil)).
+				When(order("created", nil)).
+				ThenState(map[string]int{"created": 2, "shipped": 1})
+			Expect(rec.failures).To(BeEmpty())
+		})
+
+		It("shows a diff of the read model", func() {
+			Projection(handler, func() interface{} { return totals }, FailHandler(rec.fail)).
+				Given(order("created", nil)).
+				When(order("shipped", nil)).
+				ThenState(map[string]int{"created": 1, "shipped": 2})
+			Expect(rec.failures).To(ConsistOf(And(
+				ContainSubstring(`-   "shipped": 2`),
+				ContainSubstring(`+   "shipped": 1`),
+				ContainSubstring(`    "created": 1,`),
+			)))
+		})
+
+		It("reports handler errors", func() {
+			Projection(handler, func() interface{} { return totals }, FailHandler(rec.fail)).
+				Given(order("created", nil)).
+				When(order("broken", nil)).
+				ThenError(errors.New("cannot project"))
+			Expect(rec.failures).To(BeEmpty())
+		})
+	})
+
+	Context("Stream", func() {
+		// shipper reacts to created orders by emitting a shipped event
+		shipper := func(ctx context.Context, stream base.EventStream) {
+			stream.Subscribe(ctx, "shipper", base.Select(), func(e *base.Event) error {
+				if e.Type == "created" {
+					shipped := order("shipped", nil)
+					_, err := stream.Emit(&shipped)
+					return err
+				}
+				return nil
+			})
+		}
+
+		It("waits for events emitted in reaction to When", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream := memory.NewEventStream()
+			shipper(ctx, stream)
+			Stream(stream, FailHandler(rec.fail)).
+				Given(order("registered", nil)).
+				When(order("created", nil)).
+				Then(order("created", nil), order("shipped", nil))
+			Expect(rec.failures).To(BeEmpty())
+		})
+
+		It("compares only events after the ones already in the stream", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream := memory.NewEventStream()
+			for i := 0; i < 3; i++ {
+				seeded := order("registered", nil)
+				_, err := stream.Emit(&seeded)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			shipper(ctx, stream)
+			spec := Stream(stream, FailHandler(rec.fail))
+			spec.Given(order("registered", nil)).
+				When(order("created", nil)).
+				Then(order("created", nil), order("shipped", nil))
+			spec.Given().
+				When(order("created", nil)).
+				Then(order("created", nil), order("shipped", nil))
+			Expect(rec.failures).To(BeEmpty())
+		})
+
+		It("works with the clienttest server", func() {
+			server := clienttest.NewServer()
+			defer server.Close()
+			c := server.Client()
+			var mutex sync.Mutex
+			var seen []string
+			Stream(server.EventStream(), State(func() interface{} {
+				mutex.Lock()
+				defer mutex.Unlock()
+				return seen
+			}), FailHandler(rec.fail)).
+				Given(order("created", nil)).
+				When(func() error {
+					sel, bracket := base.Select(), base.All()
+					_, err := c.Stream(context.Background(), &sel, &bracket, func(e *base.Event) error {
+						mutex.Lock()
+						defer mutex.Unlock()
+						seen = append(seen, e.Type)
+						return nil
+					})
+					return err
+				}).
+				ThenState([]string{"created"})
+			Expect(rec.failures).To(BeEmpty())
+		})
+
+		It("catches events emitted late when none are expected", func() {
+			stream := memory.NewEventStream()
+			Stream(stream, FailHandler(rec.fail), Timeout(500*time.Millisecond)).
+				Given(order("created", nil)).
+				When(func() error {
+					go func() {
+						time.Sleep(50 * time.Millisecond)
+						shipped := order("shipped", nil)
+						stream.Emit(&shipped)
+					}()
+					return nil
+				}).
+				Then()
+			Expect(rec.failures).To(ConsistOf(ContainSubstring("#1 unexpected: orders.1/shipped")))
+		})
+
+		It("measures the timeout with the given Clock", func() {
+			clock := base.NewFakeClock(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Stream(memory.NewEventStream(), FailHandler(rec.fail), Clock(clock), Timeout(time.Hour)).
+					Given().
+					When(func() error { return nil }).
+					Then()
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			clock.Advance(time.Hour)
+			Eventually(done).Should(BeClosed())
+			Expect(rec.failures).To(BeEmpty())
+		})
+
+		It("fails after the timeout", func() {
+			Stream(memory.NewEventStream(), FailHandler(rec.fail), Timeout(50*time.Millisecond)).
+				Given().
+				When(order("created", nil)).
+				Then(order("created", nil), order("shipped", nil))
+			Expect(rec.failures).To(ConsistOf(ContainSubstring("#2 missing:    orders.1/shipped")))
+		})
+	})
+})
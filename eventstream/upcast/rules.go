@@ -0,0 +1,63 @@
+package upcast
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// Rule declaratively describes how to transform a Payload of the given Type from Version to Version+1.
+type Rule struct {
+	Type     string                 `json:"type" yaml:"type"`
+	Version  int                    `json:"version" yaml:"version"`
+	Rename   map[string]string      `json:"rename,omitempty" yaml:"rename,omitempty"`
+	Defaults map[string]interface{} `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	Remove   []string               `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// Upcaster returns an Upcaster which renames fields, removes fields and fills in defaults (in that order).
+func (r Rule) Upcaster() Upcaster {
+	return func(payload map[string]interface{}) (map[string]interface{}, error) {
+		for from, to := range r.Rename {
+			if value, ok := payload[from]; ok {
+				payload[to] = value
+				delete(payload, from)
+			}
+		}
+		for _, field := range r.Remove {
+			delete(payload, field)
+		}
+		for field, value := range r.Defaults {
+			if _, ok := payload[field]; !ok {
+				payload[field] = value
+			}
+		}
+		return payload, nil
+	}
+}
+
+// ReadRules registers all Rules read from the given JSON array.
+func (r *Registry) ReadRules(reader io.Reader) error {
+	var rules []Rule
+	if err := json.NewDecoder(reader).Decode(&rules); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		version := rule.Version
+		if version < 1 {
+			version = 1
+		}
+		r.Register(rule.Type, version, rule.Upcaster())
+	}
+	return nil
+}
+
+// ReadRulesFile registers all Rules read from the given JSON file.
+func (r *Registry) ReadRulesFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return r.ReadRules(file)
+}
@@ -0,0 +1,120 @@
+package upcast
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+// DefaultVersionField is the Payload field carrying the schema version of an Event.
+const DefaultVersionField = "schema_version"
+
+// Upcaster transforms the Payload of an Event from one schema version to the next.
+type Upcaster func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// Registry holds Upcasters per Event Type and schema version. Events without a version field are considered to be
+// of version 1.
+type Registry struct {
+	versionField string
+	upcasters    map[string]map[int]Upcaster
+}
+
+type Option = func(r *Registry)
+
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		versionField: DefaultVersionField,
+		upcasters:    make(map[string]map[int]Upcaster),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func VersionField(name string) Option {
+	return func(r *Registry) {
+		r.versionField = name
+	}
+}
+
+// Register adds an Upcaster which transforms Payloads of the given Type from version to version+1.
+func (r *Registry) Register(eventType string, version int, upcaster Upcaster) {
+	versions, ok := r.upcasters[eventType]
+	if !ok {
+		versions = make(map[int]Upcaster)
+		r.upcasters[eventType] = versions
+	}
+	versions[version] = upcaster
+}
+
+// CurrentVersion returns the latest known schema version of the given Type.
+func (r *Registry) CurrentVersion(eventType string) int {
+	current := 1
+	for version := range r.upcasters[eventType] {
+		if version+1 > current {
+			current = version + 1
+		}
+	}
+	return current
+}
+
+// Upcast transforms the Payload of the given Event to the latest known schema version of its Type. The Upcasters work
+// on a copy of the Payload, so the Event is left untouched if one of them fails.
+func (r *Registry) Upcast(event *base.Event) error {
+	versions, ok := r.upcasters[event.Type]
+	if !ok {
+		return nil
+	}
+	version, err := r.versionOf(event)
+	if err != nil {
+		return err
+	}
+	upcast := event.Clone()
+	payload := upcast.Payload
+	upcasted := false
+	for {
+		upcaster, ok := versions[version]
+		if !ok {
+			break
+		}
+		if payload == nil {
+			payload = make(map[string]interface{})
+		}
+		if payload, err = upcaster(payload); err != nil {
+			return fmt.Errorf("upcasting %s from version %d: %w", event.Type, version, err)
+		}
+		version++
+		upcasted = true
+	}
+	if upcasted {
+		if payload == nil {
+			payload = make(map[string]interface{})
+		}
+		payload[r.versionField] = version
+		upcast.Payload = payload
+		*event = *upcast
+	}
+	return nil
+}
+
+func (r *Registry) versionOf(event *base.Event) (int, error) {
+	value, ok := event.Payload[r.versionField]
+	if !ok {
+		return 1, nil
+	}
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case json.Number:
+		i, err := v.Int64()
+		return int(i), err
+	default:
+		return 0, fmt.Errorf("invalid schema version %v in event %d", value, event.Sequence)
+	}
+}
@@ -0,0 +1,13 @@
+package upcast
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestUpcast(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Upcast Suite")
+}
@@ -0,0 +1,72 @@
+package upcast
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+)
+
+var _ = Describe("Upcast", func() {
+	renameName := func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["full_name"] = payload["name"]
+		delete(payload, "name")
+		return payload, nil
+	}
+	addCurrency := func(payload map[string]interface{}) (map[string]interface{}, error) {
+		payload["currency"] = "EUR"
+		return payload, nil
+	}
+
+	It("leaves Events of unknown Types untouched", func() {
+		r := NewRegistry()
+		r.Register("created", 1, renameName)
+		ev := &base.Event{Type: "deleted", Payload: map[string]interface{}{"name": "Max"}}
+		Expect(r.Upcast(ev)).To(Succeed())
+		Expect(ev.Payload).To(Equal(map[string]interface{}{"name": "Max"}))
+	})
+	It("upcasts unversioned Events through the whole chain", func() {
+		r := NewRegistry()
+		r.Register("created", 1, renameName)
+		r.Register("created", 2, addCurrency)
+		Expect(r.CurrentVersion("created")).To(Equal(3))
+		ev := &base.Event{Type: "created", Payload: map[string]interface{}{"name": "Max"}}
+		Expect(r.Upcast(ev)).To(Succeed())
+		Expect(ev.Payload).To(Equal(map[string]interface{}{"full_name": "Max", "currency": "EUR", DefaultVersionField: 3}))
+	})
+	It("starts upcasting at the Event's version", func() {
+		r := NewRegistry(VersionField("v"))
+		r.Register("created", 1, renameName)
+		r.Register("created", 2, addCurrency)
+		ev := &base.Event{Type: "created", Payload: map[string]interface{}{"full_name": "Max", "v": float64(2)}}
+		Expect(r.Upcast(ev)).To(Succeed())
+		Expect(ev.Payload).To(Equal(map[string]interface{}{"full_name": "Max", "currency": "EUR", "v": 3}))
+	})
+	It("rejects invalid versions", func() {
+		r := NewRegistry()
+		r.Register("created", 1, renameName)
+		ev := &base.Event{Type: "created", Payload: map[string]interface{}{DefaultVersionField: "one"}}
+		Expect(r.Upcast(ev)).NotTo(Succeed())
+	})
+	It("leaves the Event untouched if an Upcaster fails", func() {
+		r := NewRegistry()
+		r.Register("created", 1, renameName)
+		r.Register("created", 2, func(payload map[string]interface{}) (map[string]interface{}, error) {
+			return nil, errors.New("failure")
+		})
+		ev := &base.Event{Type: "created", Payload: map[string]interface{}{"name": "Max", "address": map[string]interface{}{"city": "Berlin"}}}
+		Expect(r.Upcast(ev)).To(MatchError(ContainSubstring("failure")))
+		Expect(ev.Payload).To(Equal(map[string]interface{}{"name": "Max", "address": map[string]interface{}{"city": "Berlin"}}))
+	})
+	It("reads declarative Rules", func() {
+		r := NewRegistry()
+		rules := `[{"type": "created", "version": 1, "rename": {"name": "full_name"}, "remove": ["legacy"], "defaults": {"currency": "EUR"}}]`
+		Expect(r.ReadRules(strings.NewReader(rules))).To(Succeed())
+		ev := &base.Event{Type: "created", Payload: map[string]interface{}{"name": "Max", "legacy": true, "currency": "USD"}}
+		Expect(r.Upcast(ev)).To(Succeed())
+		Expect(ev.Payload).To(Equal(map[string]interface{}{"full_name": "Max", "currency": "USD", DefaultVersionField: 2}))
+	})
+})
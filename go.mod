@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/eiannone/keyboard v0.0.0-20200508000154-caf4b762e807
 	github.com/golang/protobuf v1.5.2
+	github.com/klauspost/compress v1.15.9
 	github.com/mtrense/soil v0.5.0
 	github.com/onsi/ginkgo v1.16.0
 	github.com/onsi/gomega v1.11.0
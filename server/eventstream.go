@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/rpc"
+)
+
+func (s *Server) Emit(ctx context.Context, event *rpc.Event) (*rpc.Published, error) {
+	sequence, err := s.stream.Emit(rpc.ProtoToEvent(event))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpc.Published{
+		Sequence: sequence,
+	}, nil
+}
+
+func (s *Server) Stream(req *rpc.StreamRequest, srv rpc.EventStream_StreamServer) error {
+	bracket := base.All()
+	if req.Bracket != nil {
+		bracket = *rpc.ProtoToBracket(req.Bracket)
+	}
+	return toStatus(s.stream.Stream(srv.Context(), selectorFromProto(req.Selector), bracket, sender(srv.Send)))
+}
+
+func (s *Server) Listen(req *rpc.ListenRequest, srv rpc.EventStream_ListenServer) error {
+	return toStatus(s.stream.Listen(srv.Context(), selectorFromProto(req.Selector), sender(srv.Send)))
+}
+
+func (s *Server) Subscribe(req *rpc.SubscriptionRequest, srv rpc.EventStream_SubscribeServer) error {
+	if req.PersistentClientId == "" {
+		return status.Error(codes.InvalidArgument, "missing persistent client id")
+	}
+	sub, err := s.stream.Subscribe(srv.Context(), req.PersistentClientId, selectorFromProto(req.Selector), sender(srv.Send))
+	if err != nil {
+		return toStatus(err)
+	}
+	return toStatus(sub.Wait())
+}
+
+func (s *Server) Acknowledge(srv rpc.EventStream_AcknowledgeServer) error {
+	for {
+		ack, err := srv.Recv()
+		if err == io.EOF {
+			return srv.SendAndClose(&emptypb.Empty{})
+		} else if err != nil {
+			return err
+		}
+		if err := s.stream.Acknowledge(ack.PersistentClientId, ack.Sequence); err != nil {
+			return toStatus(err)
+		}
+	}
+}
+
+func sender(send func(*rpc.Event) error) base.EventHandler {
+	return func(e *base.Event) error {
+		return send(rpc.EventToProto(e))
+	}
+}
+
+func selectorFromProto(sel *rpc.Selector) base.Selector {
+	if sel == nil {
+		return base.Select()
+	}
+	return *rpc.ProtoToSelector(sel)
+}
+
+// toStatus translates errors of the EventStream into gRPC status errors.
+func toStatus(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, base.ErrSequenceNotFound), errors.Is(err, base.ErrSubscriptionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, base.ErrSubscriptionActive):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
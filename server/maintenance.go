@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ticker-es/client-go/rpc"
+)
+
+func (s *Server) GetServerState(ctx context.Context, _ *emptypb.Empty) (*rpc.ServerState, error) {
+	return &rpc.ServerState{
+		Uptime:          int64(time.Since(s.startTime).Seconds()),
+		ConnectionCount: uint32(atomic.LoadInt32(&s.connections)),
+		EventCount:      s.stream.LastSequence(),
+	}, nil
+}
+
+// Shutdown stops the Server gracefully after the response has been sent, forcing it down after the grace period
+// (in seconds).
+func (s *Server) Shutdown(ctx context.Context, params *rpc.ShutdownParameters) (*emptypb.Empty, error) {
+	go func() {
+		s.GracefulStop(time.Duration(params.GracePeriod) * time.Second)
+		if s.onShutdown != nil {
+			s.onShutdown()
+		}
+	}()
+	return &emptypb.Empty{}, nil
+}
+
+// GetSubscriptions returns one SubscriptionState per Subscription whose persistent ID matches the (glob) pattern. The
+// rpc protocol doesn't carry the state of a Subscription yet, so the entries are empty until it does.
+func (s *Server) GetSubscriptions(ctx context.Context, req *rpc.SubscriptionsRequest) (*rpc.SubscriptionsResponse, error) {
+	var states []*rpc.SubscriptionState
+	for _, sub := range s.stream.Subscriptions() {
+		if req.ClientIDPattern != nil {
+			if ok, _ := path.Match(*req.ClientIDPattern, sub.PersistentID()); !ok {
+				continue
+			}
+		}
+		states = append(states, &rpc.SubscriptionState{})
+	}
+	return &rpc.SubscriptionsResponse{
+		Subscriptions: states,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/rpc"
+)
+
+// Server implements the EventStream and Maintenance services on top of any base.EventStream.
+type Server struct {
+	rpc.UnimplementedEventStreamServer
+	rpc.UnimplementedMaintenanceServer
+	stream        base.EventStream
+	serverOptions []grpc.ServerOption
+	grpcServer    *grpc.Server
+	startTime     time.Time
+	connections   int32
+	onShutdown    func()
+}
+
+type Option = func(s *Server)
+
+func NewServer(stream base.EventStream, opts ...Option) *Server {
+	s := &Server{
+		stream:    stream,
+		startTime: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.grpcServer = grpc.NewServer(append(s.serverOptions, grpc.StatsHandler(&connectionCounter{server: s}))...)
+	rpc.RegisterEventStreamServer(s.grpcServer, s)
+	rpc.RegisterMaintenanceServer(s.grpcServer, s)
+	return s
+}
+
+func ServerOptions(opts ...grpc.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOptions = append(s.serverOptions, opts...)
+	}
+}
+
+// OnShutdown registers a function which is called once the Server has been shut down via the Maintenance service.
+func OnShutdown(f func()) Option {
+	return func(s *Server) {
+		s.onShutdown = f
+	}
+}
+
+// Serve accepts connections on the listener until the Server is stopped.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpcServer.Serve(listener)
+}
+
+// Stop closes all connections immediately.
+func (s *Server) Stop() {
+	s.grpcServer.Stop()
+}
+
+// GracefulStop stops accepting new connections and waits for running calls to finish, but at most for the given
+// grace period.
+func (s *Server) GracefulStop(gracePeriod time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(gracePeriod):
+		s.grpcServer.Stop()
+	}
+}
+
+// EventStream returns the underlying EventStream.
+func (s *Server) EventStream() base.EventStream {
+	return s.stream
+}
+
+// connectionCounter keeps track of the number of open client connections.
+type connectionCounter struct {
+	server *Server
+}
+
+func (c *connectionCounter) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (c *connectionCounter) HandleRPC(ctx context.Context, rpcStats stats.RPCStats) {}
+
+func (c *connectionCounter) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (c *connectionCounter) HandleConn(ctx context.Context, connStats stats.ConnStats) {
+	switch connStats.(type) {
+	case *stats.ConnBegin:
+		atomic.AddInt32(&c.server.connections, 1)
+	case *stats.ConnEnd:
+		atomic.AddInt32(&c.server.connections, -1)
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/ticker-es/client-go/client"
+	"github.com/ticker-es/client-go/eventstream/base"
+	"github.com/ticker-es/client-go/eventstream/memory"
+	"github.com/ticker-es/client-go/rpc"
+)
+
+var _ = Describe("Server", func() {
+	var srv *Server
+	var cl *client.Client
+	var maintenance rpc.MaintenanceClient
+	var address string
+
+	BeforeEach(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address = listener.Addr().String()
+		srv = NewServer(memory.NewEventStream())
+		go srv.Serve(listener)
+		cl = client.NewClient(address, client.Insecure())
+		Expect(cl.Connect()).To(Succeed())
+		conn, err := grpc.Dial(address, grpc.WithInsecure())
+		Expect(err).NotTo(HaveOccurred())
+		maintenance = rpc.NewMaintenanceClient(conn)
+	})
+	AfterEach(func() {
+		srv.Stop()
+	})
+
+	emit := func(aggregate ...string) {
+		_, err := cl.Emit(context.Background(), base.Event{Aggregate: aggregate, Type: "created", OccurredAt: time.Now()})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("emits and streams Events", func() {
+		emit("test", "1")
+		emit("test", "2")
+		emit("other", "1")
+		var sequences []int64
+		count, err := cl.Stream(context.Background(), &base.Selector{Aggregate: []string{"test"}}, &base.Bracket{NextSequence: 1, LastSequence: -1}, func(e *base.Event) error {
+			sequences = append(sequences, e.Sequence)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(2)))
+		Expect(sequences).To(Equal([]int64{1, 2}))
+	})
+
+	It("delivers Events to subscribers and records their acknowledgements", func() {
+		emit("test", "1")
+		received := make(chan int64, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go cl.Subscribe(ctx, "subscriber", &base.Selector{}, func(e *base.Event) error {
+			received <- e.Sequence
+			return nil
+		})
+		Eventually(received).Should(Receive(Equal(int64(1))))
+		emit("test", "2")
+		Eventually(received).Should(Receive(Equal(int64(2))))
+		Eventually(func() int64 {
+			sequence, _ := srv.EventStream().Subscriptions()[0].LastAcknowledgedSequence()
+			return sequence
+		}).Should(Equal(int64(2)))
+	})
+
+	It("delivers newly emitted Events to listeners", func() {
+		emit("test", "1")
+		received := make(chan int64, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go cl.Listen(ctx, &base.Selector{}, func(e *base.Event) error {
+			received <- e.Sequence
+			return nil
+		})
+		Eventually(func() int {
+			emit("test", "2")
+			return len(received)
+		}).ShouldNot(BeZero())
+		Expect(<-received).To(BeNumerically(">", 1))
+	})
+
+	It("reports its state", func() {
+		emit("test", "1")
+		state, err := maintenance.GetServerState(context.Background(), &emptypb.Empty{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.EventCount).To(Equal(int64(1)))
+		Expect(state.ConnectionCount).To(BeNumerically(">=", 2))
+	})
+
+	It("lists the subscriptions matching a pattern", func() {
+		for _, id := range []string{"billing-1", "billing-2", "shipping"} {
+			_, err := srv.EventStream().Subscribe(context.Background(), id, base.Select(), func(e *base.Event) error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+		resp, err := maintenance.GetSubscriptions(context.Background(), &rpc.SubscriptionsRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Subscriptions).To(HaveLen(3))
+		pattern := "billing-*"
+		resp, err = maintenance.GetSubscriptions(context.Background(), &rpc.SubscriptionsRequest{ClientIDPattern: &pattern})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Subscriptions).To(HaveLen(2))
+	})
+
+	It("shuts down on request", func() {
+		stopped := make(chan struct{})
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		srv := NewServer(memory.NewEventStream(), OnShutdown(func() { close(stopped) }))
+		go srv.Serve(listener)
+		conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rpc.NewMaintenanceClient(conn).Shutdown(context.Background(), &rpc.ShutdownParameters{GracePeriod: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(stopped, 2*time.Second).Should(BeClosed())
+	})
+})
@@ -8,7 +8,7 @@ import (
 
 func CancelContextOnSignals(parent context.Context, signals ...os.Signal) (context.Context, func()) {
 	ctx, cancel := context.WithCancel(parent)
-	signalChannel := make(chan os.Signal)
+	signalChannel := make(chan os.Signal, 1)
 	signal.Notify(signalChannel, signals...)
 	go func() {
 		<-signalChannel